@@ -0,0 +1,67 @@
+// Command ddl writes the DDL of the models of the models package as a SQL
+// script for review.
+//
+// Usage:
+//
+//	ddl [-o schema.sql] [-tablespace TS] [-index-tablespace TS] [-storage clause]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oraddl"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+func main() {
+	out := flag.String("o", "", "output file (default: standard output)")
+	tablespace := flag.String("tablespace", "", "tablespace of tables, exactly as stored")
+	indexTablespace := flag.String("index-tablespace", "", "tablespace of indexes, exactly as stored")
+	storage := flag.String("storage", "", `physical attributes appended to CREATE TABLE, e.g. "PCTFREE 20"`)
+	flag.Parse()
+
+	var opts []oraddl.Option
+	if *tablespace != "" {
+		opts = append(opts, oraddl.WithTablespace(*tablespace))
+	}
+	if *indexTablespace != "" {
+		opts = append(opts, oraddl.WithIndexTablespace(*indexTablespace))
+	}
+	if *storage != "" {
+		opts = append(opts, oraddl.WithStorage(*storage))
+	}
+
+	if err := run(*out, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, opts []oraddl.Option) error {
+	// The script is rendered without a database; bun only needs a handle,
+	// which never connects.
+	cfg := &oraconn.Config{Host: "localhost"}
+	sqldb, err := cfg.Open()
+	if err != nil {
+		return err
+	}
+	db := bun.NewDB(sqldb, oracledialect.New())
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return oraddl.WriteScript(w, db, models.All(), opts...)
+}
@@ -0,0 +1,83 @@
+// Command genmodels generates bun models from the tables of an existing
+// Oracle schema.
+//
+// Usage:
+//
+//	genmodels [-db user/password@host:port/service] [-schema OWNER] [-tables A,B] [-pkg models] [-o file.go]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oragen"
+	"github.com/lake-of-dreams/bundb-oracle/oraschema"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+func main() {
+	dsn := flag.String("db", envOr("ORACLE_DSN", "BUNAPP/bunapp123@localhost:1521/FREEPDB1"),
+		"Easy Connect string of a user that can see the tables")
+	owner := flag.String("schema", "", "schema to read, exactly as stored (default: the connecting user)")
+	tables := flag.String("tables", "", "comma-separated table names, exactly as stored (default: all)")
+	pkg := flag.String("pkg", "models", "package name of the generated file")
+	out := flag.String("o", "", "output file (default: standard output)")
+	flag.Parse()
+
+	if err := run(context.Background(), *dsn, *owner, *tables, *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dsn, owner, tableList, pkg, out string) error {
+	cfg, err := oraconn.ParseEZConnect(dsn)
+	if err != nil {
+		return err
+	}
+	cfg.Session.CurrentSchema = owner
+	sqldb, err := cfg.Open()
+	if err != nil {
+		return err
+	}
+	db := bun.NewDB(sqldb, oracledialect.New())
+	defer db.Close()
+
+	var names []string
+	if tableList != "" {
+		names = strings.Split(tableList, ",")
+	}
+	tables, err := oraschema.ReadTables(ctx, db, names...)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if tables[name] == nil {
+			return fmt.Errorf("table %q not found", name)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return oragen.Generate(w, pkg, tables)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,144 @@
+// Command migrate applies the migrations of the migrations package.
+//
+// Usage:
+//
+//	migrate [-db user/password@host:port/service] up|down|status
+//	migrate [-dir migrations] create [-go] name
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/lake-of-dreams/bundb-oracle/migrations"
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oramigrate"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+	"github.com/uptrace/bun/migrate"
+)
+
+func main() {
+	dsn := flag.String("db", envOr("ORACLE_DSN", "BUNAPP/bunapp123@localhost:1521/FREEPDB1"),
+		"Easy Connect string of the schema owner")
+	dir := flag.String("dir", "migrations", "directory new migrations are created in")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] up|down|status|create [-go] name\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(context.Background(), *dsn, *dir, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dsn, dir, cmd string, args []string) error {
+	if cmd == "create" {
+		return create(dir, args)
+	}
+
+	cfg, err := oraconn.ParseEZConnect(dsn)
+	if err != nil {
+		return err
+	}
+	sqldb, err := cfg.Open()
+	if err != nil {
+		return err
+	}
+	db := bun.NewDB(sqldb, oracledialect.New())
+	defer db.Close()
+
+	migrator := oramigrate.NewMigrator(db, migrations.Migrations)
+	if err := migrator.Init(ctx); err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "up":
+		group, err := migrator.Up(ctx)
+		printGroup("applied", group)
+		return err
+	case "down":
+		group, err := migrator.Down(ctx)
+		printGroup("rolled back", group)
+		return err
+	case "status":
+		return status(ctx, migrator)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func create(dir string, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	goMigration := fs.Bool("go", false, "create a Go migration instead of SQL files")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: create [-go] name")
+	}
+	name := fs.Arg(0)
+
+	if *goMigration {
+		ms := migrate.NewMigrations(migrate.WithMigrationsDirectory(dir))
+		mf, err := migrate.NewMigrator(nil, ms).CreateGoMigration(context.Background(), name)
+		if err != nil {
+			return err
+		}
+		fmt.Println("created", mf.Path)
+		return nil
+	}
+
+	paths, err := oramigrate.NewMigrator(nil, migrations.Migrations).CreateSQL(dir, name)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Println("created", path)
+	}
+	return nil
+}
+
+func status(ctx context.Context, migrator *oramigrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		state := "pending"
+		if s.IsApplied() {
+			state = fmt.Sprintf("applied (group %d, %s)", s.GroupID, s.MigratedAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Printf("%s_%s: %s\n", s.Name, s.Comment, state)
+		if f := s.Failure; f != nil {
+			fmt.Printf("  FAILED %s at %s", f.Direction, f.FailedAt.Format("2006-01-02 15:04:05"))
+			if f.Statement > 0 {
+				fmt.Printf(" on statement %d; statements before it were committed", f.Statement)
+			}
+			fmt.Printf("\n  %s\n", f.Error)
+		}
+	}
+	return nil
+}
+
+func printGroup(verb string, group *migrate.MigrationGroup) {
+	if group == nil || len(group.Migrations) == 0 {
+		return
+	}
+	fmt.Printf("%s %s\n", verb, group)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
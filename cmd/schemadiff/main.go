@@ -0,0 +1,69 @@
+// Command schemadiff compares the models of the models package with the
+// tables of the connected schema and reports every difference.
+//
+// Usage:
+//
+//	schemadiff [-db user/password@host:port/service] [-fix]
+//
+// It exits with status 3 when the schema has drifted.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oraschema"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+func main() {
+	dsn := flag.String("db", envOr("ORACLE_DSN", "BUNAPP/bunapp123@localhost:1521/FREEPDB1"),
+		"Easy Connect string of the schema owner")
+	fix := flag.Bool("fix", false, "print statements that reconcile the schema with the models")
+	flag.Parse()
+
+	diffs, err := run(context.Background(), *dsn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(diffs) == 0 {
+		return
+	}
+
+	for _, d := range diffs {
+		if *fix {
+			fmt.Printf("-- %s\n%s;\n\n", d.String(), d.Fix)
+		} else {
+			fmt.Println(d.String())
+		}
+	}
+	os.Exit(3)
+}
+
+func run(ctx context.Context, dsn string) ([]oraschema.Difference, error) {
+	cfg, err := oraconn.ParseEZConnect(dsn)
+	if err != nil {
+		return nil, err
+	}
+	sqldb, err := cfg.Open()
+	if err != nil {
+		return nil, err
+	}
+	db := bun.NewDB(sqldb, oracledialect.New())
+	defer db.Close()
+
+	return oraschema.Compare(ctx, db, models.All()...)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,20 @@
+// Package oratx runs code in a transaction on Oracle.
+package oratx
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+// Run runs fn in a transaction unless db is one already. bun nests
+// transactions with RELEASE SAVEPOINT, which Oracle does not have.
+func Run(ctx context.Context, db bun.IDB, fn func(ctx context.Context, db bun.IDB) error) error {
+	switch db.(type) {
+	case bun.Tx, *bun.Tx:
+		return fn(ctx, db)
+	}
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(ctx, tx)
+	})
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log"
 	"os"
@@ -14,20 +13,20 @@ import (
 	"github.com/containers/podman/v5/pkg/bindings/images"
 	"github.com/containers/podman/v5/pkg/specgen"
 
+	"github.com/lake-of-dreams/bundb-oracle/migrations"
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oramigrate"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/oraretry"
+	"github.com/lake-of-dreams/bundb-oracle/provision"
+	"github.com/lake-of-dreams/bundb-oracle/repository"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
-	go_ora "github.com/sijms/go-ora/v2"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/oracledialect"
 )
 
-type Product struct {
-	bun.BaseModel `bun:"table:products,alias:u"`
-
-	ID    int64 `bun:",pk,autoincrement"`
-	Name  string
-	Price float64
-}
-
 func main() {
 	// Initialize connection to podman
 	conn, err := bindings.NewConnection(context.Background(), "unix://"+os.Getenv("XDG_RUNTIME_DIR")+"/podman/podman.sock")
@@ -151,7 +150,23 @@ func main() {
 
 	// Initialize connection to the database
 	log.Println("Connecting to database...")
-	sqldb, err := sql.Open("oracle", go_ora.BuildUrl("localhost", 1521, "FREEPDB1", "SYSTEM", "oracle123", nil))
+	cfg := oraconn.Config{
+		Host:            "localhost",
+		Port:            1521,
+		Service:         "FREEPDB1",
+		User:            "SYSTEM",
+		Password:        "oracle123",
+		ConnectTimeout:  30 * time.Second,
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		Session: oraconn.Session{
+			NLSDateFormat: "YYYY-MM-DD HH24:MI:SS",
+			TimeZone:      "UTC",
+			Module:        "bundb-oracle",
+		},
+	}
+	sqldb, err := cfg.Open()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -159,59 +174,102 @@ func main() {
 
 	log.Println("Connected to database...")
 
-	db := bun.NewDB(sqldb, oracledialect.New())
+	adminDB := bun.NewDB(sqldb, oracledialect.New())
+
+	// Provision a dedicated application user instead of working in SYSTEM.
+	// An existing user is kept along with its data.
+	log.Println("Provisioning application user...")
+	appCfg, err := provision.CreateAppUser(context.Background(), adminDB, cfg, provision.AppUser{
+		Name:     "BUNAPP",
+		Password: "bunapp123",
+		Datafile: "/opt/oracle/oradata/FREE/FREEPDB1/bunapp01.dbf",
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	adminDB.Close()
 
-	log.Println("Creating table...")
-	// Drop and create tables.
-	err = db.ResetModel(context.Background(), (*Product)(nil))
+	appsqldb, err := appCfg.Open()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	log.Println("Created table...")
+	log.Println("Connected as application user...")
 
-	// Insert multiple products (bulk-insert).
-	log.Println("Inserting data to the table...")
-	p1 := Product{Name: "apple", Price: 5.99}
-	p2 := Product{Name: "orange", Price: 4.99}
-	products := []Product{p1, p2}
-	_, err = db.NewInsert().Model(&products).Exec(context.Background())
+	db := bun.NewDB(appsqldb, oracledialect.New())
+
+	log.Println("Migrating schema...")
+	migrator := oramigrate.NewMigrator(db, migrations.Migrations)
+	err = migrator.Init(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	_, err = migrator.Up(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
+	log.Println("Migrated schema...")
+
+	if err := demo(context.Background(), db); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// demo runs the CRUD flow against the products table.
+func demo(ctx context.Context, db *bun.DB) error {
+	repo := repository.NewProductRepository(db)
+	// Inserts and updates are stamped with this user by oraaudit.
+	ctx = oraaudit.WithUser(ctx, "demo")
+
+	// Insert multiple products (bulk-insert).
+	log.Println("Inserting data to the table...")
+	products := []models.Product{
+		{Name: "apple", Price: oranum.MustParse("5.99")},
+		{Name: "orange", Price: oranum.MustParse("4.99")},
+	}
+	if err := repo.BulkCreate(ctx, products); err != nil {
+		return err
+	}
+
 	log.Println("Inserted data to the table...")
 
 	// Read all products
 	log.Println("Reading data from the table...")
-	var allProducts []Product
-	err = db.NewSelect().Model(&allProducts).Scan(context.Background(), &allProducts)
+	allProducts, err := repo.List(ctx, repository.ProductListOptions{
+		Sort: []repository.Sort{{Column: "name"}},
+	})
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return err
 	}
 
 	for _, product := range allProducts {
-		fmt.Printf("Product %d: %s - $%.2f\n", product.ID, product.Name, product.Price)
+		fmt.Printf("Product %d: %s - $%s\n", product.ID, product.Name, product.Price.Round(2))
 	}
 	log.Println("Read data from the table...")
 
-	// Update a product
+	// Update a product; BulkCreate set the IDs.
 	log.Println("Updating data in the table...")
-	allProducts[0].Name = "banana"
-	_, err = db.NewUpdate().Model(&allProducts[0]).Column("name").WherePK().Exec(context.Background())
+	apple := &products[0]
+	apple.Name = "banana"
+	err = oraretry.RunInTx(ctx, db, nil, func(ctx context.Context, tx bun.Tx) error {
+		return repository.NewProductRepository(tx).Update(ctx, apple, "name")
+	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("Updated data in the table...")
 
-	// Delete a product
+	// Delete a product; it is soft-deleted and stays in the table.
 	log.Println("Deleting data from the table...")
-	_, err = db.NewDelete().Model(&allProducts[1]).WherePK().Exec(context.Background())
-	if err != nil {
-		log.Fatal(err)
+	if err := repo.Delete(ctx, products[1].ID); err != nil {
+		return err
 	}
 	log.Println("Deleted data from the table...")
+	return nil
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/repository"
+)
+
+// newRecorder returns a recorder answering the demo's statements. Rules
+// added by script come first and so take precedence.
+func newRecorder(t *testing.T, script func(rec *orafake.Recorder)) *orafake.Recorder {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	if script != nil {
+		script(rec)
+	}
+	rec.On(`user_tab_identity_columns`).Return([]string{"SEQUENCE_NAME", "GENERATION_TYPE"},
+		[]any{"ISEQ$$_72000", "BY DEFAULT"},
+	)
+	rec.On(`NEXTVAL`).Return([]string{"NEXTVAL"}, []any{1}, []any{2})
+	columns := []string{"id", "name", "price"}
+	rec.On(`^SELECT .* FROM "products"`).Return(columns,
+		[]any{1, "apple", 5.99},
+		[]any{2, "orange", 4.99},
+	)
+	rec.On(`^(UPDATE|DELETE) `).Affect(1)
+	return rec
+}
+
+var (
+	timestamp   = regexp.MustCompile(`TO_TIMESTAMP\('[^']*'`)
+	timestampTZ = regexp.MustCompile(`FROM_TZ\(TIMESTAMP '[^']*', '[^']*'\)`)
+)
+
+// stamp and stampTZ are the literals of a time.Time and of an oratime.Time
+// as queries leaves them.
+const (
+	stamp   = `TO_TIMESTAMP('...', 'YYYY-MM-DD HH24:MI:SS.FF')`
+	stampTZ = `FROM_TZ(TIMESTAMP '...', '...')`
+)
+
+// queries returns the statements rec saw, with timestamp literals replaced
+// by stamp and stampTZ, so that they compare equal from run to run.
+func queries(rec *orafake.Recorder) []string {
+	got := rec.Queries()
+	for i, q := range got {
+		q = timestamp.ReplaceAllLiteralString(q, `TO_TIMESTAMP('...'`)
+		got[i] = timestampTZ.ReplaceAllLiteralString(q, stampTZ)
+	}
+	return got
+}
+
+func TestDemo(t *testing.T) {
+	rec := newRecorder(t, nil)
+	db := rec.DB()
+	defer db.Close()
+
+	if err := demo(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`SELECT sequence_name, generation_type FROM user_tab_identity_columns WHERE table_name = 'products' AND column_name = 'id'`,
+		`SELECT "ISEQ$$_72000".NEXTVAL FROM dual CONNECT BY LEVEL <= 2`,
+		`INSERT INTO "products" ("id", "name", "price", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES ` +
+			`(1, 'apple', 5.99, 0, NULL, ` + stampTZ + `, ` + stampTZ + `, 'demo', 'demo'), ` +
+			`(2, 'orange', 4.99, 0, NULL, ` + stampTZ + `, ` + stampTZ + `, 'demo', 'demo')`,
+		`SELECT "u"."id", "u"."name", "u"."price", "u"."version", "u"."deleted_at", "u"."created_at", "u"."updated_at", "u"."created_by", "u"."updated_by" ` +
+			`FROM "products" "u" WHERE "u"."deleted_at" IS NULL ORDER BY "name" ASC, "id" ASC`,
+		orafake.Begin,
+		`UPDATE "products" SET "name" = 'banana', "updated_at" = ` + stampTZ + `, "updated_by" = 'demo', "version" = 1 WHERE ("version" = 0) AND "products"."deleted_at" IS NULL AND ("id" = 1)`,
+		orafake.Commit,
+		`UPDATE "products" SET "deleted_at" = ` + stamp + ` WHERE "products"."deleted_at" IS NULL AND ("id" = 2)`,
+	}
+	if got := queries(rec); !slices.Equal(got, want) {
+		t.Errorf("got queries\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestDemoUniqueViolation(t *testing.T) {
+	rec := newRecorder(t, func(rec *orafake.Recorder) {
+		rec.On(`^INSERT INTO "products"`).Fail(orafake.OraError(1, "unique constraint (BUNAPP.SYS_C008) violated"))
+	})
+	db := rec.DB()
+	defer db.Close()
+
+	err := demo(context.Background(), db)
+	if !errors.Is(err, repository.ErrConflict) || !oraerr.IsUniqueViolation(err) {
+		t.Fatalf("got %v, want a conflict from a unique violation", err)
+	}
+	if n := len(rec.Calls()); n != 3 {
+		t.Errorf("got %d statements up to the failed insert, want 3", n)
+	}
+}
+
+func TestDemoRetriesUpdate(t *testing.T) {
+	rec := newRecorder(t, func(rec *orafake.Recorder) {
+		rec.On(`^UPDATE "products"`).Times(1).Fail(orafake.OraError(8177, "can't serialize access for this transaction"))
+	})
+	db := rec.DB()
+	defer db.Close()
+
+	if err := demo(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	var updates, rollbacks int
+	for _, q := range rec.Queries() {
+		switch {
+		case q == orafake.Rollback:
+			rollbacks++
+		case strings.HasPrefix(q, `UPDATE "products" SET "name"`):
+			updates++
+		}
+	}
+	if updates != 2 || rollbacks != 1 {
+		t.Errorf("got %d updates and %d rollbacks, want 2 and 1", updates, rollbacks)
+	}
+}
+
+func TestDemoConnectionLost(t *testing.T) {
+	rec := newRecorder(t, func(rec *orafake.Recorder) {
+		rec.On(`^UPDATE "products" SET "deleted_at"`).Fail(orafake.OraError(3113, "end-of-file on communication channel"))
+	})
+	db := rec.DB()
+	defer db.Close()
+
+	if err := demo(context.Background(), db); !oraerr.IsConnectionLost(err) {
+		t.Fatalf("got %v, want a lost connection", err)
+	}
+}
@@ -0,0 +1,7 @@
+-- Identifiers are quoted lower-case to match the names bun generates.
+CREATE TABLE "products" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR2(255),
+  "price" DOUBLE PRECISION,
+  PRIMARY KEY ("id")
+);
@@ -0,0 +1,4 @@
+ALTER TABLE "products" ADD ("price_old" DOUBLE PRECISION);
+UPDATE "products" SET "price_old" = "price";
+ALTER TABLE "products" DROP COLUMN "price";
+ALTER TABLE "products" RENAME COLUMN "price_old" TO "price";
@@ -0,0 +1,6 @@
+-- Prices become exact NUMBER(10,2). Oracle cannot narrow a column holding
+-- data, so the values are copied into a new column, rounded to cents.
+ALTER TABLE "products" ADD ("price_new" NUMBER(10,2));
+UPDATE "products" SET "price_new" = ROUND("price", 2);
+ALTER TABLE "products" DROP COLUMN "price";
+ALTER TABLE "products" RENAME COLUMN "price_new" TO "price";
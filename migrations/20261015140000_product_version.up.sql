@@ -0,0 +1,2 @@
+-- Version column for optimistic locking with oralock.
+ALTER TABLE "products" ADD ("version" INTEGER DEFAULT 0 NOT NULL);
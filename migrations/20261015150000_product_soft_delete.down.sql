@@ -0,0 +1,3 @@
+-- Soft-deleted products are removed rather than brought back to life.
+DELETE FROM "products" WHERE "deleted_at" IS NOT NULL;
+ALTER TABLE "products" DROP COLUMN "deleted_at";
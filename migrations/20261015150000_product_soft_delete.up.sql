@@ -0,0 +1,2 @@
+-- Soft delete: bun sets "deleted_at" instead of deleting the row.
+ALTER TABLE "products" ADD ("deleted_at" TIMESTAMP);
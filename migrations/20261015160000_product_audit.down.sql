@@ -0,0 +1 @@
+ALTER TABLE "products" DROP ("created_at", "updated_at", "created_by", "updated_by");
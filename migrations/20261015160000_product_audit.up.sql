@@ -0,0 +1,7 @@
+-- Audit columns set by oraaudit on insert and update.
+ALTER TABLE "products" ADD (
+  "created_at" TIMESTAMP WITH TIME ZONE,
+  "updated_at" TIMESTAMP WITH TIME ZONE,
+  "created_by" VARCHAR2(255),
+  "updated_by" VARCHAR2(255)
+);
@@ -0,0 +1,22 @@
+// Package migrations holds the schema migrations of the demo application.
+// SQL migrations are embedded from this directory; Go migrations register
+// themselves with Migrations from init functions in this package.
+package migrations
+
+import (
+	"embed"
+
+	"github.com/lake-of-dreams/bundb-oracle/oramigrate"
+	"github.com/uptrace/bun/migrate"
+)
+
+//go:embed *.sql
+var sqlMigrations embed.FS
+
+var Migrations = migrate.NewMigrations()
+
+func init() {
+	if err := oramigrate.Discover(Migrations, sqlMigrations); err != nil {
+		panic(err)
+	}
+}
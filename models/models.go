@@ -0,0 +1,36 @@
+// Package models holds the bun models of the demo application.
+package models
+
+import (
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/uptrace/bun"
+)
+
+type Product struct {
+	bun.BaseModel `bun:"table:products,alias:u"`
+
+	ID    int64 `bun:",pk,autoincrement"`
+	Name  string
+	Price oranum.Decimal `bun:",type:number(10,2)"`
+	// Version is incremented by every update, for optimistic locking with
+	// oralock.
+	Version int64 `bun:",notnull,default:0" oralock:"version"`
+	// DeletedAt is set by bun's Delete instead of removing the row, and
+	// deleted products are left out of selects unless the query asks for
+	// them with WhereAllWithDeleted or WhereDeleted. ForceDelete removes the
+	// row.
+	DeletedAt time.Time `bun:",soft_delete,nullzero"`
+	// Audit stamps inserts and updates with their time and user.
+	oraaudit.Audit
+}
+
+// All returns a nil pointer to every model, for tools that work on the
+// whole schema such as drift detection.
+func All() []any {
+	return []any{
+		(*Product)(nil),
+	}
+}
@@ -0,0 +1 @@
+CREATE INDEX "orders_customer_idx" ON "orders" ("customer_id")
@@ -0,0 +1 @@
+CREATE TABLE "orders" ("id" INTEGER GENERATED BY DEFAULT AS IDENTITY, "customer_id" INTEGER, "total" VARCHAR2(255), "placed_at" VARCHAR2(255), "due_on" date, "handling" VARCHAR2(255), PRIMARY KEY ("id"), FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION)
@@ -0,0 +1,34 @@
+-- Generated by the ddl command from the registered bun models.
+
+CREATE TABLE "products" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+  "name" VARCHAR2(255),
+  "price" number(10,2),
+  "version" INTEGER DEFAULT 0 NOT NULL,
+  "deleted_at" TIMESTAMP,
+  "created_at" TIMESTAMP WITH TIME ZONE,
+  "updated_at" TIMESTAMP WITH TIME ZONE,
+  "created_by" VARCHAR2(255),
+  "updated_by" VARCHAR2(255),
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE "customers" (
+  "id" INTEGER GENERATED ALWAYS AS IDENTITY (START WITH 1000 CACHE 50) NOT NULL,
+  "email" VARCHAR2(255),
+  "last_seen_at" timestamp with local time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE "orders_seq" INCREMENT BY 10 NOCACHE;
+
+CREATE TABLE "orders" (
+  "id" INTEGER DEFAULT "orders_seq".NEXTVAL NOT NULL,
+  "customer_id" INTEGER NOT NULL,
+  "total" NUMBER,
+  "placed_at" TIMESTAMP WITH TIME ZONE,
+  "due_on" date,
+  "handling" INTERVAL DAY(9) TO SECOND(6),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("customer_id") REFERENCES "customers" ("id")
+);
@@ -0,0 +1 @@
+DELETE FROM "products" WHERE ("id" = 1) RETURNING id
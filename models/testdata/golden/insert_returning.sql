@@ -0,0 +1 @@
+INSERT INTO "products" ("id", "name", "price", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES (1, 'apple', 5.99, NULL, NULL, NULL, NULL, NULL)
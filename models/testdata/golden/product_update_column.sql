@@ -0,0 +1 @@
+UPDATE "products" SET "name" = 'apple' WHERE "products"."deleted_at" IS NULL AND ("id" = 1)
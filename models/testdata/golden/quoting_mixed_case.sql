@@ -0,0 +1 @@
+SELECT "UPPER_COL", "lower_col" FROM "MixedCase"
@@ -0,0 +1 @@
+SELECT "o"."id", "o"."customer_id", "o"."total", "o"."placed_at", "o"."due_on", "o"."handling", "customer"."id" AS "customer__id", "customer"."email" AS "customer__email", "customer"."last_seen_at" AS "customer__last_seen_at" FROM "orders" "o" LEFT JOIN "customers" AS "customer" ON ("customer"."id" = "o"."customer_id")
@@ -0,0 +1 @@
+SELECT count(*) FROM "products" "u" WHERE "u"."deleted_at" IS NULL
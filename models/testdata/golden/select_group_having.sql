@@ -0,0 +1 @@
+SELECT "o"."customer_id", sum("total") AS total FROM "orders" "o" GROUP BY "customer_id" HAVING (sum("total") > 100)
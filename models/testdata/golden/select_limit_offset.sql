@@ -0,0 +1 @@
+SELECT "u"."id", "u"."name", "u"."price", "u"."version", "u"."deleted_at", "u"."created_at", "u"."updated_at", "u"."created_by", "u"."updated_by" FROM "products" "u" WHERE "u"."deleted_at" IS NULL ORDER BY "id" LIMIT 10 OFFSET 20
@@ -0,0 +1 @@
+SELECT "c"."id", "c"."email", "c"."last_seen_at" FROM "customers" "c" WHERE ("id" IN (SELECT "o"."customer_id" FROM "orders" "o" WHERE ("total" > 100)))
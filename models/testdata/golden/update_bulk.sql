@@ -0,0 +1 @@
+WITH "_data" ("id", "name", "price", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") AS (VALUES (1, 'apple', 5.99, 0, NULL, NULL, NULL, NULL, NULL), (2, 'orange', 4.99, 0, NULL, NULL, NULL, NULL, NULL)) UPDATE "products" SET "price" = _data."price" FROM _data WHERE (u.id = _data."id") AND "products"."deleted_at" IS NULL
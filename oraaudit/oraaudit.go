@@ -0,0 +1,152 @@
+// Package oraaudit stamps bun models with when and by whom their rows were
+// inserted and last updated. Embed Timestamps or Audit in a model:
+//
+//	type Product struct {
+//		bun.BaseModel `bun:"table:products,alias:u"`
+//		oraaudit.Audit
+//		...
+//	}
+//
+// Their BeforeAppendModel hook sets the fields on every insert and update
+// bun runs, for a struct or for each element of a slice. The user comes from
+// the context, set with WithUser. Time is the client's, or the database's
+// SYSTIMESTAMP with WithServerTime.
+//
+// A model that needs a BeforeAppendModel hook of its own shadows the one of
+// the embedded struct, and must call it.
+package oraaudit
+
+import (
+	"context"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+type ctxKey int
+
+const (
+	userKey ctxKey = iota
+	clockKey
+)
+
+// clock is the time source stored in a context.
+type clock struct {
+	now    func() time.Time
+	server bool
+}
+
+// WithUser returns a context whose inserts and updates are stamped with
+// user.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// User returns the user set with WithUser, or "".
+func User(ctx context.Context) string {
+	user, _ := ctx.Value(userKey).(string)
+	return user
+}
+
+// WithTime returns a context whose inserts and updates are stamped with the
+// time now returns instead of time.Now, e.g. a fixed clock in tests.
+func WithTime(ctx context.Context, now func() time.Time) context.Context {
+	c := clockFrom(ctx)
+	c.now = now
+	return context.WithValue(ctx, clockKey, c)
+}
+
+// WithServerTime returns a context whose inserts and updates write
+// SYSTIMESTAMP, so that rows are stamped by one clock whatever the client.
+// The fields of the model still get the client time, which is what
+// statements that bind values rather than let bun write them, such as those
+// of oraload, store.
+func WithServerTime(ctx context.Context) context.Context {
+	c := clockFrom(ctx)
+	c.server = true
+	return context.WithValue(ctx, clockKey, c)
+}
+
+func clockFrom(ctx context.Context) clock {
+	c, ok := ctx.Value(clockKey).(clock)
+	if !ok {
+		c.now = time.Now
+	}
+	return c
+}
+
+// Timestamps records when a row was inserted and last updated, in the time
+// zone of the clock. CreatedAt is never updated.
+type Timestamps struct {
+	CreatedAt oratime.Time `bun:",nullzero,skipupdate"`
+	UpdatedAt oratime.Time `bun:",nullzero"`
+}
+
+var _ bun.BeforeAppendModelHook = (*Timestamps)(nil)
+
+func (t *Timestamps) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	c := clockFrom(ctx)
+	now := oratime.Time{Time: c.now()}
+	switch q := query.(type) {
+	case *bun.InsertQuery:
+		t.CreatedAt, t.UpdatedAt = now, now
+		if c.server {
+			q.Value("created_at", "SYSTIMESTAMP").Value("updated_at", "SYSTIMESTAMP")
+		}
+	case *bun.UpdateQuery:
+		t.UpdatedAt = now
+		if c.server {
+			q.Value("updated_at", "SYSTIMESTAMP")
+		}
+	}
+	return nil
+}
+
+// Audit records when and by whom a row was inserted and last updated.
+// CreatedAt and CreatedBy are never updated.
+type Audit struct {
+	Timestamps
+	CreatedBy string `bun:",nullzero,skipupdate"`
+	UpdatedBy string `bun:",nullzero"`
+}
+
+var _ bun.BeforeAppendModelHook = (*Audit)(nil)
+
+func (a *Audit) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if err := a.Timestamps.BeforeAppendModel(ctx, query); err != nil {
+		return err
+	}
+	user := User(ctx)
+	switch query.(type) {
+	case *bun.InsertQuery:
+		a.CreatedBy, a.UpdatedBy = user, user
+	case *bun.UpdateQuery:
+		a.UpdatedBy = user
+	}
+	return nil
+}
+
+// UpdateColumns returns the columns of table that Timestamps and Audit set
+// on update. bun only writes the columns an update names, so an update with
+// a column mask must name these too for them to be stamped.
+func UpdateColumns(table *schema.Table) []string {
+	var columns []string
+	for _, name := range []string{"updated_at", "updated_by"} {
+		if _, ok := table.FieldMap[name]; ok {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// BeforeAppend runs the BeforeAppendModel hooks of the model of q, for code
+// that builds an insert or update with bun but executes it itself, which
+// bun's hooks would otherwise miss.
+func BeforeAppend(ctx context.Context, q bun.Query) error {
+	if hook, ok := q.GetModel().(bun.BeforeAppendModelHook); ok {
+		return hook.BeforeAppendModel(ctx, q)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package oraaudit_test
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/uptrace/bun"
+)
+
+type Note struct {
+	bun.BaseModel `bun:"table:notes"`
+
+	ID   int64 `bun:",pk"`
+	Text string
+	oraaudit.Audit
+}
+
+var (
+	at    = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	stamp = `FROM_TZ(TIMESTAMP '2026-10-15 12:00:00', 'UTC')`
+)
+
+func newDB(t *testing.T) (*bun.DB, *orafake.Recorder, context.Context) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	ctx := oraaudit.WithTime(oraaudit.WithUser(context.Background(), "alice"), func() time.Time { return at })
+	return db, rec, ctx
+}
+
+func TestStamps(t *testing.T) {
+	db, rec, ctx := newDB(t)
+	rec.On(`^UPDATE`).Affect(1)
+
+	note := &Note{ID: 1, Text: "hello"}
+	if _, err := db.NewInsert().Model(note).Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !note.CreatedAt.Equal(at) || !note.UpdatedAt.Equal(at) || note.CreatedBy != "alice" || note.UpdatedBy != "alice" {
+		t.Errorf("got %+v after insert", note.Audit)
+	}
+
+	later := at.Add(time.Hour)
+	ctx = oraaudit.WithTime(oraaudit.WithUser(ctx, "bob"), func() time.Time { return later })
+	if _, err := db.NewUpdate().Model(note).WherePK().Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !note.CreatedAt.Equal(at) || !note.UpdatedAt.Equal(later) || note.CreatedBy != "alice" || note.UpdatedBy != "bob" {
+		t.Errorf("got %+v after update", note.Audit)
+	}
+
+	want := []string{
+		`INSERT INTO "notes" ("id", "text", "created_at", "updated_at", "created_by", "updated_by") ` +
+			`VALUES (1, 'hello', ` + stamp + `, ` + stamp + `, 'alice', 'alice')`,
+		// created_at and created_by are skipupdate.
+		`UPDATE "notes" SET "text" = 'hello', ` +
+			`"updated_at" = FROM_TZ(TIMESTAMP '2026-10-15 13:00:00', 'UTC'), "updated_by" = 'bob' ` +
+			`WHERE ("id" = 1)`,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	db, rec, ctx := newDB(t)
+	rec.On(`^UPDATE`).Affect(1)
+	ctx = oraaudit.WithServerTime(ctx)
+
+	notes := []Note{{ID: 1, Text: "a"}, {ID: 2, Text: "b"}}
+	if _, err := db.NewInsert().Model(&notes).Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// A column mask names the stamped columns too.
+	columns := append([]string{"text"}, oraaudit.UpdateColumns(db.Table(reflect.TypeOf((*Note)(nil))))...)
+	if _, err := db.NewUpdate().Model(&notes[0]).Column(columns...).WherePK().Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !notes[1].CreatedAt.Equal(at) {
+		t.Errorf("got CreatedAt %v, want the client time %v", notes[1].CreatedAt, at)
+	}
+
+	want := []string{
+		`INSERT INTO "notes" ("id", "text", "created_at", "updated_at", "created_by", "updated_by") VALUES ` +
+			`(1, 'a', SYSTIMESTAMP, SYSTIMESTAMP, 'alice', 'alice'), (2, 'b', SYSTIMESTAMP, SYSTIMESTAMP, 'alice', 'alice')`,
+		`UPDATE "notes" SET "text" = 'a', "updated_at" = SYSTIMESTAMP, "updated_by" = 'alice' WHERE ("id" = 1)`,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
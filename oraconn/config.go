@@ -0,0 +1,151 @@
+// Package oraconn builds go-ora connection strings from typed options and
+// opens database/sql pools configured from them.
+package oraconn
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+
+	go_ora "github.com/sijms/go-ora/v2"
+)
+
+// DriverName is the database/sql driver name registered by go-ora.
+const DriverName = "oracle"
+
+const defaultPort = 1521
+
+// LobFetch selects how go-ora fetches LOB columns.
+type LobFetch string
+
+const (
+	// LobFetchInline reads LOB contents together with the row (go-ora default).
+	LobFetchInline LobFetch = "INLINE"
+	// LobFetchStream reads LOB contents with separate round trips after the row.
+	LobFetchStream LobFetch = "STREAM"
+)
+
+// Config describes how to reach an Oracle database and how to size the
+// database/sql pool in front of it.
+type Config struct {
+	Host    string
+	Port    int
+	Service string
+	// Descriptor is a full connect descriptor, for example one resolved from
+	// tnsnames.ora. When set it takes precedence over Host, Port and Service.
+	Descriptor string
+
+	User     string
+	Password string
+
+	// ConnectTimeout is rounded up to whole seconds.
+	ConnectTimeout time.Duration
+	// SSL enables TCPS. SSLVerify additionally verifies the server certificate.
+	SSL       bool
+	SSLVerify bool
+	// WalletPath is the directory holding cwallet.sso / ewallet.p12.
+	WalletPath     string
+	WalletPassword string
+	TraceFile      string
+	PrefetchRows   int
+	LobFetch       LobFetch
+	// Options holds any other go-ora URL option, keyed by its name
+	// (e.g. "PROGRAM" or "LANGUAGE"). Typed fields win over Options.
+	Options map[string]string
+
+	// Session is applied to every physical connection opened by the pool.
+	Session Session
+
+	// Pool settings applied to the *sql.DB by Open. Zero keeps the
+	// database/sql default.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// URLOptions returns the go-ora URL options derived from c.
+func (c *Config) URLOptions() map[string]string {
+	opts := make(map[string]string, len(c.Options)+8)
+	for k, v := range c.Options {
+		opts[k] = v
+	}
+	if c.ConnectTimeout > 0 {
+		secs := (c.ConnectTimeout + time.Second - 1) / time.Second
+		opts["CONNECTION TIMEOUT"] = strconv.FormatInt(int64(secs), 10)
+	}
+	if c.SSL {
+		opts["SSL"] = "enable"
+		opts["SSL VERIFY"] = strconv.FormatBool(c.SSLVerify)
+	}
+	if c.WalletPath != "" {
+		opts["WALLET"] = c.WalletPath
+	}
+	if c.WalletPassword != "" {
+		opts["WALLET PASSWORD"] = c.WalletPassword
+	}
+	if c.TraceFile != "" {
+		opts["TRACE FILE"] = c.TraceFile
+	}
+	if c.PrefetchRows > 0 {
+		opts["PREFETCH_ROWS"] = strconv.Itoa(c.PrefetchRows)
+	}
+	if c.LobFetch != "" {
+		opts["LOB FETCH"] = string(c.LobFetch)
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts
+}
+
+// DSN returns the go-ora connection URL for c.
+func (c *Config) DSN() string {
+	if c.Descriptor != "" {
+		return go_ora.BuildJDBC(c.User, c.Password, c.Descriptor, c.URLOptions())
+	}
+	port := c.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return go_ora.BuildUrl(c.Host, port, c.Service, c.User, c.Password, c.URLOptions())
+}
+
+// Open opens a *sql.DB for c and applies its pool settings. Like sql.Open it
+// does not connect; call PingContext to verify the configuration.
+func (c *Config) Open() (*sql.DB, error) {
+	if c.Descriptor == "" && c.Host == "" {
+		return nil, fmt.Errorf("oraconn: either Host or Descriptor is required")
+	}
+	db := sql.OpenDB(c.Connector())
+	c.ApplyPool(db)
+	return db, nil
+}
+
+// Connector returns a driver.Connector for c that applies c.Session to each
+// new connection.
+func (c *Config) Connector() driver.Connector {
+	connector := go_ora.NewConnector(c.DSN())
+	if stmts := c.Session.InitStatements(); len(stmts) > 0 {
+		return NewConnector(connector, stmts...)
+	}
+	return connector
+}
+
+// ApplyPool applies the pool settings of c to db.
+func (c *Config) ApplyPool(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
@@ -0,0 +1,91 @@
+package oraconn_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+)
+
+func TestConfigURLOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  oraconn.Config
+		want map[string]string
+	}{
+		{"none", oraconn.Config{Host: "db"}, nil},
+		{"timeout", oraconn.Config{ConnectTimeout: 30 * time.Second},
+			map[string]string{"CONNECTION TIMEOUT": "30"}},
+		// Partial seconds are rounded up rather than down to no timeout.
+		{"timeout rounded up", oraconn.Config{ConnectTimeout: 1500 * time.Millisecond},
+			map[string]string{"CONNECTION TIMEOUT": "2"}},
+		{"tcps", oraconn.Config{SSL: true},
+			map[string]string{"SSL": "enable", "SSL VERIFY": "false"}},
+		{"tcps verified", oraconn.Config{SSL: true, SSLVerify: true},
+			map[string]string{"SSL": "enable", "SSL VERIFY": "true"}},
+		// SSLVerify means nothing without SSL.
+		{"verify without tcps", oraconn.Config{SSLVerify: true}, nil},
+		{"wallet", oraconn.Config{SSL: true, WalletPath: "/etc/wallet", WalletPassword: "secret"},
+			map[string]string{"SSL": "enable", "SSL VERIFY": "false",
+				"WALLET": "/etc/wallet", "WALLET PASSWORD": "secret"}},
+		{"typed fields win", oraconn.Config{
+			PrefetchRows: 500,
+			LobFetch:     oraconn.LobFetchStream,
+			Options:      map[string]string{"PROGRAM": "billing", "PREFETCH_ROWS": "10"},
+		}, map[string]string{"PROGRAM": "billing", "PREFETCH_ROWS": "500", "LOB FETCH": "STREAM"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.URLOptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDSN(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       oraconn.Config
+		host      string
+		path      string
+		query     url.Values
+		user, pwd string
+	}{
+		{"default port", oraconn.Config{Host: "db", Service: "FREEPDB1", User: "scott", Password: "tiger"},
+			"db:1521", "/FREEPDB1", url.Values{}, "scott", "tiger"},
+		{"tcps with wallet", oraconn.Config{
+			Host: "db", Port: 2484, Service: "FREEPDB1", User: "scott", Password: "t@ger",
+			ConnectTimeout: 10 * time.Second, SSL: true, SSLVerify: true, WalletPath: "/etc/wallet",
+		}, "db:2484", "/FREEPDB1", url.Values{
+			"CONNECTION TIMEOUT": {"10"}, "SSL": {"enable"}, "SSL VERIFY": {"true"}, "WALLET": {"/etc/wallet"},
+		}, "scott", "t@ger"},
+		{"descriptor", oraconn.Config{
+			Descriptor: "(DESCRIPTION = (ADDRESS = (HOST = db)))", Host: "ignored", User: "scott", Password: "tiger",
+			ConnectTimeout: time.Second,
+		}, ":0", "/", url.Values{
+			"CONNECTION TIMEOUT": {"1"}, "connStr": {"(DESCRIPTION = (ADDRESS = (HOST = db)))"},
+		}, "scott", "tiger"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := tt.cfg.DSN()
+			u, err := url.Parse(dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := url.ParseQuery(u.RawQuery)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pwd, _ := u.User.Password()
+			if u.Scheme != "oracle" || u.Host != tt.host || u.Path != tt.path ||
+				u.User.Username() != tt.user || pwd != tt.pwd || !reflect.DeepEqual(query, tt.query) {
+				t.Errorf("got %s, want host %s, path %s, user %s/%s and options %v",
+					dsn, tt.host, tt.path, tt.user, tt.pwd, tt.query)
+			}
+		})
+	}
+}
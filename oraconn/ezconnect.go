@@ -0,0 +1,98 @@
+package oraconn
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseEZConnect parses an Easy Connect string of the form
+//
+//	[user[/password]@][//]host[:port][/service_name][:server][/instance_name][?option=value&...]
+//
+// or the older host:port:sid form naming a SID rather than a service, which
+// is passed to go-ora as its SID option. Query options are copied to
+// Config.Options as go-ora URL options.
+func ParseEZConnect(s string) (*Config, error) {
+	c := &Config{Port: defaultPort}
+	rest := strings.TrimSpace(s)
+
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		cred := rest[:i]
+		rest = rest[i+1:]
+		if user, pass, ok := strings.Cut(cred, "/"); ok {
+			c.User, c.Password = user, pass
+		} else {
+			c.User = cred
+		}
+	}
+
+	if i := strings.Index(rest, "?"); i >= 0 {
+		q, err := url.ParseQuery(rest[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("oraconn: invalid EZConnect options in %q: %w", s, err)
+		}
+		c.Options = make(map[string]string, len(q))
+		for k := range q {
+			c.Options[k] = q.Get(k)
+		}
+		rest = rest[:i]
+	}
+
+	rest = strings.TrimPrefix(rest, "//")
+	hostPort, path, _ := strings.Cut(rest, "/")
+
+	host := hostPort
+	if strings.HasPrefix(hostPort, "[") {
+		end := strings.Index(hostPort, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("oraconn: invalid EZConnect host in %q", s)
+		}
+		host = hostPort[1:end]
+		hostPort = hostPort[end+1:]
+		if !strings.HasPrefix(hostPort, ":") {
+			hostPort = ""
+		}
+	} else if h, _, ok := strings.Cut(hostPort, ":"); ok {
+		host = h
+		hostPort = hostPort[len(h):]
+	} else {
+		hostPort = ""
+	}
+	if host == "" {
+		return nil, fmt.Errorf("oraconn: missing host in EZConnect string %q", s)
+	}
+	c.Host = host
+
+	port, sid, hasSID := strings.Cut(strings.TrimPrefix(hostPort, ":"), ":")
+	if hasSID {
+		if sid == "" || path != "" {
+			return nil, fmt.Errorf("oraconn: invalid SID in EZConnect string %q", s)
+		}
+		if c.Options == nil {
+			c.Options = make(map[string]string, 1)
+		}
+		c.Options["SID"] = sid
+	}
+	if port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("oraconn: invalid port in EZConnect string %q", s)
+		}
+		c.Port = n
+	}
+
+	if path != "" {
+		service, instance, _ := strings.Cut(path, "/")
+		service, _, _ = strings.Cut(service, ":") // server type is not configurable in go-ora
+		c.Service = service
+		if instance != "" {
+			if c.Options == nil {
+				c.Options = make(map[string]string, 1)
+			}
+			c.Options["INSTANCE NAME"] = instance
+		}
+	}
+	return c, nil
+}
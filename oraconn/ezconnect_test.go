@@ -0,0 +1,58 @@
+package oraconn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+)
+
+func TestParseEZConnect(t *testing.T) {
+	tests := []struct {
+		in   string
+		want oraconn.Config
+	}{
+		{"dbhost", oraconn.Config{Host: "dbhost", Port: 1521}},
+		{"//dbhost:1522", oraconn.Config{Host: "dbhost", Port: 1522}},
+		{"dbhost/FREEPDB1", oraconn.Config{Host: "dbhost", Port: 1521, Service: "FREEPDB1"}},
+		{"dbhost:1522/FREEPDB1", oraconn.Config{Host: "dbhost", Port: 1522, Service: "FREEPDB1"}},
+		{"scott/tiger@//dbhost:1522/FREEPDB1", oraconn.Config{
+			Host: "dbhost", Port: 1522, Service: "FREEPDB1", User: "scott", Password: "tiger",
+		}},
+		{"scott@dbhost/FREEPDB1", oraconn.Config{Host: "dbhost", Port: 1521, Service: "FREEPDB1", User: "scott"}},
+		// The last @ separates the credentials, so passwords may contain one.
+		{"scott/p@ss@dbhost/FREEPDB1", oraconn.Config{
+			Host: "dbhost", Port: 1521, Service: "FREEPDB1", User: "scott", Password: "p@ss",
+		}},
+		// A service, then the server type go-ora does not take, then an
+		// instance.
+		{"dbhost:1521/sales.example.com:dedicated/inst1", oraconn.Config{
+			Host: "dbhost", Port: 1521, Service: "sales.example.com",
+			Options: map[string]string{"INSTANCE NAME": "inst1"},
+		}},
+		// A SID after a colon instead of a service after a slash.
+		{"dbhost:1521:ORCL", oraconn.Config{Host: "dbhost", Port: 1521, Options: map[string]string{"SID": "ORCL"}}},
+		{"[::1]:1522:ORCL", oraconn.Config{Host: "::1", Port: 1522, Options: map[string]string{"SID": "ORCL"}}},
+		{"[::1]/FREEPDB1", oraconn.Config{Host: "::1", Port: 1521, Service: "FREEPDB1"}},
+		{"dbhost/FREEPDB1?connect_timeout=5&TRACE FILE=trace.log", oraconn.Config{
+			Host: "dbhost", Port: 1521, Service: "FREEPDB1",
+			Options: map[string]string{"connect_timeout": "5", "TRACE FILE": "trace.log"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := oraconn.ParseEZConnect(tt.in)
+		if err != nil {
+			t.Errorf("ParseEZConnect(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ParseEZConnect(%q) = %+v, want %+v", tt.in, *got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "scott@", ":1521/FREEPDB1", "dbhost:port/FREEPDB1", "[::1/FREEPDB1", "dbhost:1521:", "dbhost:1521:ORCL/FREEPDB1"} {
+		if _, err := oraconn.ParseEZConnect(in); err == nil {
+			t.Errorf("ParseEZConnect(%q): got no error", in)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package oraconn
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Session lists per-session settings applied to every new physical
+// connection, so queries behave the same whichever pooled connection they
+// run on.
+type Session struct {
+	NLSDateFormat        string
+	NLSTimestampFormat   string
+	NLSTimestampTZFormat string
+	// TimeZone is a region name such as "Europe/Berlin" or an offset such as
+	// "+00:00".
+	TimeZone string
+	// CurrentSchema is quoted, so ordinary schemas must be given upper-case.
+	CurrentSchema string
+
+	// Module and Action are reported through DBMS_APPLICATION_INFO and show
+	// up in V$SESSION.
+	Module string
+	Action string
+	// ClientIdentifier is set with DBMS_SESSION.SET_IDENTIFIER.
+	ClientIdentifier string
+
+	// Statements are executed after the settings above, in order.
+	Statements []string
+}
+
+// InitStatements returns the statements that apply s to a session.
+func (s *Session) InitStatements() []string {
+	var stmts []string
+	alter := func(param, value string) {
+		if value != "" {
+			stmts = append(stmts, fmt.Sprintf("ALTER SESSION SET %s = %s", param, quoteLiteral(value)))
+		}
+	}
+	alter("NLS_DATE_FORMAT", s.NLSDateFormat)
+	alter("NLS_TIMESTAMP_FORMAT", s.NLSTimestampFormat)
+	alter("NLS_TIMESTAMP_TZ_FORMAT", s.NLSTimestampTZFormat)
+	alter("TIME_ZONE", s.TimeZone)
+	if s.CurrentSchema != "" {
+		stmts = append(stmts, "ALTER SESSION SET CURRENT_SCHEMA = "+quoteIdent(s.CurrentSchema))
+	}
+	if s.Module != "" || s.Action != "" {
+		stmts = append(stmts, fmt.Sprintf("BEGIN DBMS_APPLICATION_INFO.SET_MODULE(%s, %s); END;",
+			quoteLiteral(s.Module), quoteLiteral(s.Action)))
+	}
+	if s.ClientIdentifier != "" {
+		stmts = append(stmts, fmt.Sprintf("BEGIN DBMS_SESSION.SET_IDENTIFIER(%s); END;",
+			quoteLiteral(s.ClientIdentifier)))
+	}
+	return append(stmts, s.Statements...)
+}
+
+// Connector wraps a driver.Connector and runs a fixed list of statements on
+// every connection it opens.
+type Connector struct {
+	driver.Connector
+	stmts []string
+}
+
+var _ driver.Connector = (*Connector)(nil)
+
+// NewConnector returns a Connector running stmts on each new connection
+// opened by base.
+func NewConnector(base driver.Connector, stmts ...string) *Connector {
+	return &Connector{Connector: base, stmts: stmts}
+}
+
+// Connect opens a connection with the wrapped connector and initializes it.
+// The connection is closed if any statement fails.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range c.stmts {
+		if err := execConn(ctx, conn, stmt); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("oraconn: session init %q: %w", stmt, err)
+		}
+	}
+	return conn, nil
+}
+
+func execConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		return err
+	}
+
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Prepare(query)
+	}
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,137 @@
+package oraconn_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"slices"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+)
+
+func TestSessionInitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		session oraconn.Session
+		want    []string
+	}{
+		{"empty", oraconn.Session{}, nil},
+		{"nls", oraconn.Session{
+			NLSDateFormat:        "YYYY-MM-DD",
+			NLSTimestampFormat:   "YYYY-MM-DD HH24:MI:SS.FF",
+			NLSTimestampTZFormat: "YYYY-MM-DD HH24:MI:SS.FF TZR",
+			TimeZone:             "Europe/Berlin",
+		}, []string{
+			"ALTER SESSION SET NLS_DATE_FORMAT = 'YYYY-MM-DD'",
+			"ALTER SESSION SET NLS_TIMESTAMP_FORMAT = 'YYYY-MM-DD HH24:MI:SS.FF'",
+			"ALTER SESSION SET NLS_TIMESTAMP_TZ_FORMAT = 'YYYY-MM-DD HH24:MI:SS.FF TZR'",
+			"ALTER SESSION SET TIME_ZONE = 'Europe/Berlin'",
+		}},
+		{"schema", oraconn.Session{CurrentSchema: `APP"X`}, []string{
+			`ALTER SESSION SET CURRENT_SCHEMA = "APP""X"`,
+		}},
+		{"application info", oraconn.Session{
+			Module:           "billing",
+			Action:           "O'Brien's run",
+			ClientIdentifier: "user'1",
+			Statements:       []string{"ALTER SESSION ENABLE PARALLEL DML"},
+		}, []string{
+			"BEGIN DBMS_APPLICATION_INFO.SET_MODULE('billing', 'O''Brien''s run'); END;",
+			"BEGIN DBMS_SESSION.SET_IDENTIFIER('user''1'); END;",
+			"ALTER SESSION ENABLE PARALLEL DML",
+		}},
+		{"module only", oraconn.Session{Module: "billing"}, []string{
+			"BEGIN DBMS_APPLICATION_INFO.SET_MODULE('billing', ''); END;",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.InitStatements(); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// closeCounter counts the connections of a connector that are closed.
+type closeCounter struct {
+	driver.Connector
+	closed int
+}
+
+func (c *closeCounter) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countedConn{Conn: conn, c: c}, nil
+}
+
+// countedConn hides the ExecerContext of the wrapped conn, so Connector
+// falls back to preparing the statements.
+type countedConn struct {
+	driver.Conn
+	c *closeCounter
+}
+
+func (conn *countedConn) Close() error {
+	conn.c.closed++
+	return conn.Conn.Close()
+}
+
+func TestConnectorOncePerConnection(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	stmts := (&oraconn.Session{TimeZone: "UTC", Module: "billing"}).InitStatements()
+	db := sql.OpenDB(oraconn.NewConnector(&closeCounter{Connector: rec}, stmts...))
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	conn1, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn2, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn1.Close()
+	conn2.Close()
+	for range 3 {
+		if _, err := db.ExecContext(ctx, "UPDATE t SET x = 1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var inits int
+	for _, q := range rec.Queries() {
+		if slices.Contains(stmts, q) {
+			inits++
+		}
+	}
+	if inits != 2*len(stmts) {
+		t.Errorf("got %d init statements, want %d for 2 connections: %q", inits, 2*len(stmts), rec.Queries())
+	}
+}
+
+func TestConnectorInitFailure(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	rec.On(`^ALTER SESSION SET TIME_ZONE`).Fail(orafake.OraError(1882, "timezone region not found"))
+
+	base := &closeCounter{Connector: rec}
+	connector := oraconn.NewConnector(base, "ALTER SESSION SET TIME_ZONE = 'Mars/Olympus'", "SELECT 1 FROM dual")
+	conn, err := connector.Connect(context.Background())
+	if code, _ := oraerr.Code(err); conn != nil || code != 1882 {
+		t.Fatalf("got %v, %v, want ORA-01882", conn, err)
+	}
+	if base.closed != 1 {
+		t.Errorf("got %d closed connections, want 1", base.closed)
+	}
+	if got, want := rec.Queries(), []string{"ALTER SESSION SET TIME_ZONE = 'Mars/Olympus'"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
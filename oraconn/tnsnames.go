@@ -0,0 +1,199 @@
+package oraconn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrAliasNotFound is returned by LookupTNS when the alias is not
+	// defined.
+	ErrAliasNotFound = errors.New("oraconn: tns alias not found")
+	// ErrAmbiguousAlias is returned by LookupTNS when an alias without a
+	// domain matches entries in more than one domain.
+	ErrAmbiguousAlias = errors.New("oraconn: ambiguous tns alias")
+)
+
+// ParseTNSNames reads a tnsnames.ora file and returns the connect
+// descriptors keyed by upper-cased alias. IFILE entries are skipped, as a
+// reader has no directory to resolve them against; ReadTNSNames follows
+// them.
+func ParseTNSNames(r io.Reader) (map[string]string, error) {
+	entries, _, err := parseTNSNames(r)
+	return entries, err
+}
+
+// ReadTNSNames reads the tnsnames.ora file at path and the files its IFILE
+// entries include, relative paths being relative to the including file. An
+// alias the including file defines itself wins over an included one.
+func ReadTNSNames(path string) (map[string]string, error) {
+	return readTNSNames(path, map[string]bool{})
+}
+
+func readTNSNames(path string, seen map[string]bool) (map[string]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if seen[abs] {
+		return nil, fmt.Errorf("oraconn: tnsnames.ora: %s includes itself", path)
+	}
+	seen[abs] = true
+	defer delete(seen, abs)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, includes, err := parseTNSNames(f)
+	if err != nil {
+		return nil, err
+	}
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		included, err := readTNSNames(inc, seen)
+		if err != nil {
+			return nil, err
+		}
+		for alias, descriptor := range included {
+			if _, ok := entries[alias]; !ok {
+				entries[alias] = descriptor
+			}
+		}
+	}
+	return entries, nil
+}
+
+// parseTNSNames returns the descriptors of r and the paths of its IFILE
+// entries.
+func parseTNSNames(r io.Reader) (map[string]string, []string, error) {
+	var sb strings.Builder
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		sb.WriteString(line)
+		sb.WriteByte(' ')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	text := sb.String()
+
+	entries := make(map[string]string)
+	var includes []string
+	for pos := 0; pos < len(text); {
+		eq := strings.IndexByte(text[pos:], '=')
+		if eq < 0 {
+			if strings.TrimSpace(text[pos:]) != "" {
+				return nil, nil, fmt.Errorf("oraconn: tnsnames.ora: trailing text %q", strings.TrimSpace(text[pos:]))
+			}
+			break
+		}
+		aliases := text[pos : pos+eq]
+		pos += eq + 1
+
+		if strings.EqualFold(strings.TrimSpace(aliases), "IFILE") {
+			fields := strings.Fields(text[pos:])
+			if len(fields) == 0 {
+				return nil, nil, errors.New("oraconn: tnsnames.ora: IFILE without a path")
+			}
+			includes = append(includes, strings.Trim(fields[0], `"'`))
+			pos += strings.Index(text[pos:], fields[0]) + len(fields[0])
+			continue
+		}
+
+		start := strings.IndexByte(text[pos:], '(')
+		if start < 0 || strings.TrimSpace(text[pos:pos+start]) != "" {
+			return nil, nil, fmt.Errorf("oraconn: tnsnames.ora: descriptor for %q must start with '('",
+				strings.TrimSpace(aliases))
+		}
+		pos += start
+
+		end, depth := pos, 0
+		for ; end < len(text); end++ {
+			switch text[end] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if depth != 0 {
+			return nil, nil, fmt.Errorf("oraconn: tnsnames.ora: unbalanced parentheses for %q",
+				strings.TrimSpace(aliases))
+		}
+		descriptor := strings.Join(strings.Fields(text[pos:end+1]), " ")
+		pos = end + 1
+
+		for _, alias := range strings.Split(aliases, ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				entries[strings.ToUpper(alias)] = descriptor
+			}
+		}
+	}
+	return entries, includes, nil
+}
+
+// TNSNamesPath returns the tnsnames.ora location derived from $TNS_ADMIN or
+// $ORACLE_HOME/network/admin.
+func TNSNamesPath() (string, error) {
+	if dir := os.Getenv("TNS_ADMIN"); dir != "" {
+		return filepath.Join(dir, "tnsnames.ora"), nil
+	}
+	if home := os.Getenv("ORACLE_HOME"); home != "" {
+		return filepath.Join(home, "network", "admin", "tnsnames.ora"), nil
+	}
+	return "", errors.New("oraconn: neither TNS_ADMIN nor ORACLE_HOME is set")
+}
+
+// LookupTNS resolves alias from the tnsnames.ora file at TNSNamesPath,
+// following its IFILE entries, and returns a Config using its connect
+// descriptor. An alias without a domain also matches an entry defined with
+// one, e.g. "FREEPDB1" matches "FREEPDB1.EXAMPLE.COM", as long as no
+// other domain defines it too.
+func LookupTNS(alias, user, password string) (*Config, error) {
+	path, err := TNSNamesPath()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ReadTNSNames(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.ToUpper(alias)
+	descriptor, ok := entries[key]
+	if !ok {
+		var matches []string
+		for name := range entries {
+			if strings.HasPrefix(name, key+".") {
+				matches = append(matches, name)
+			}
+		}
+		if len(matches) > 1 {
+			slices.Sort(matches)
+			return nil, fmt.Errorf("%w: %s matches %s in %s",
+				ErrAmbiguousAlias, alias, strings.Join(matches, ", "), path)
+		}
+		if len(matches) == 1 {
+			descriptor, ok = entries[matches[0]], true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in %s", ErrAliasNotFound, alias, path)
+	}
+	return &Config{Descriptor: descriptor, User: user, Password: password}, nil
+}
@@ -0,0 +1,137 @@
+package oraconn_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+)
+
+const sales = "(DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = db1)(PORT = 1521)) (CONNECT_DATA = (SERVICE_NAME = sales)))"
+
+func TestParseTNSNames(t *testing.T) {
+	in := `# Production
+SALES, sales.example.com =
+  (DESCRIPTION =
+    (ADDRESS = (PROTOCOL = TCP)(HOST = db1)(PORT = 1521)) # primary
+    (CONNECT_DATA =
+      (SERVICE_NAME = sales)
+    )
+  )
+
+# Fails over between two listeners.
+hr=(DESCRIPTION=(ADDRESS_LIST=(FAILOVER=on)
+  (ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))
+  (ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521)))
+  (CONNECT_DATA=(SERVICE_NAME=hr)))
+
+IFILE = other.ora
+`
+	got, err := oraconn.ParseTNSNames(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Whitespace, newlines included, collapses to single spaces.
+	salesDescriptor := "(DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = db1)(PORT = 1521)) " +
+		"(CONNECT_DATA = (SERVICE_NAME = sales) ) )"
+	want := map[string]string{
+		"SALES":             salesDescriptor,
+		"SALES.EXAMPLE.COM": salesDescriptor,
+		"HR": "(DESCRIPTION=(ADDRESS_LIST=(FAILOVER=on) (ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521)) " +
+			"(ADDRESS=(PROTOCOL=TCP)(HOST=db2)(PORT=1521))) (CONNECT_DATA=(SERVICE_NAME=hr)))",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, in := range []string{
+		"SALES = DESCRIPTION",
+		"SALES = (DESCRIPTION = (ADDRESS = (HOST = db1))",
+		"SALES = (DESCRIPTION = (ADDRESS = (HOST = db1))) trailing",
+		"IFILE =",
+	} {
+		if _, err := oraconn.ParseTNSNames(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseTNSNames(%q): got no error", in)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookupTNS(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TNS_ADMIN", dir)
+	writeFile(t, filepath.Join(dir, "tnsnames.ora"), `
+sales.example.com = `+sales+`
+IFILE = shared/hr.ora
+`)
+	writeFile(t, filepath.Join(dir, "shared", "hr.ora"), `
+HR = (DESCRIPTION = (ADDRESS = (HOST = db2)) (CONNECT_DATA = (SERVICE_NAME = hr)))
+SALES.EXAMPLE.COM = (DESCRIPTION = (ADDRESS = (HOST = elsewhere)))
+`)
+
+	tests := []struct {
+		alias, descriptor string
+	}{
+		{"sales.example.com", sales},
+		// An alias without its domain matches too.
+		{"sales", sales},
+		// From the included file.
+		{"hr", "(DESCRIPTION = (ADDRESS = (HOST = db2)) (CONNECT_DATA = (SERVICE_NAME = hr)))"},
+	}
+	for _, tt := range tests {
+		cfg, err := oraconn.LookupTNS(tt.alias, "scott", "tiger")
+		if err != nil {
+			t.Errorf("LookupTNS(%q): %v", tt.alias, err)
+			continue
+		}
+		want := oraconn.Config{Descriptor: tt.descriptor, User: "scott", Password: "tiger"}
+		if !reflect.DeepEqual(*cfg, want) {
+			t.Errorf("LookupTNS(%q) = %+v, want %+v", tt.alias, *cfg, want)
+		}
+	}
+
+	if _, err := oraconn.LookupTNS("missing", "", ""); !errors.Is(err, oraconn.ErrAliasNotFound) {
+		t.Errorf("got %v, want ErrAliasNotFound", err)
+	}
+}
+
+func TestLookupTNSAmbiguous(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TNS_ADMIN", dir)
+	writeFile(t, filepath.Join(dir, "tnsnames.ora"), `
+APP.US = (DESCRIPTION = (ADDRESS = (HOST = us)))
+APP.EU = (DESCRIPTION = (ADDRESS = (HOST = eu)))
+`)
+
+	_, err := oraconn.LookupTNS("app", "", "")
+	if !errors.Is(err, oraconn.ErrAmbiguousAlias) || !strings.Contains(err.Error(), "APP.EU, APP.US") {
+		t.Errorf("got %v, want ErrAmbiguousAlias naming APP.EU, APP.US", err)
+	}
+	// The full alias still resolves.
+	cfg, err := oraconn.LookupTNS("app.eu", "", "")
+	if err != nil || cfg.Descriptor != "(DESCRIPTION = (ADDRESS = (HOST = eu)))" {
+		t.Errorf("got %+v, %v, want the APP.EU descriptor", cfg, err)
+	}
+}
+
+func TestReadTNSNamesIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.ora"), "IFILE = b.ora")
+	writeFile(t, filepath.Join(dir, "b.ora"), "IFILE = a.ora")
+	if _, err := oraconn.ReadTNSNames(filepath.Join(dir, "a.ora")); err == nil {
+		t.Error("got no error for an IFILE cycle")
+	}
+}
@@ -0,0 +1,183 @@
+package oraddl
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Generation modes of an identity column.
+const (
+	GeneratedByDefault       = "BY DEFAULT"
+	GeneratedByDefaultOnNull = "BY DEFAULT ON NULL"
+	GeneratedAlways          = "ALWAYS"
+)
+
+// KeyGeneration is how the values of an autoincrement column are generated,
+// as set by options in its oraddl tag, which is kept apart from the bun tag
+// as bun warns about options it does not know:
+//
+//	ID int64 `bun:",pk,autoincrement" oraddl:"identity:always,start:1000,cache:100"`
+//	ID int64 `bun:",pk,autoincrement" oraddl:"sequence:orders_seq,increment:10,nocache"`
+//
+// identity takes by_default, the default, on_null or always. With sequence
+// the column is not an identity column but defaults to the NEXTVAL of a
+// sequence of that name, which Statements creates before the table. start,
+// increment, cache and nocache configure the sequence either way.
+//
+// bun leaves autoincrement columns out of inserts on Oracle, so all three
+// generate the key of a plain insert; only a BY DEFAULT identity or a
+// sequence accepts explicit values.
+type KeyGeneration struct {
+	// Generation is the mode of an identity column, or "" for a sequence.
+	Generation string
+	// Sequence holds the sequence options; its Name is empty for an identity
+	// column, whose sequence Oracle names.
+	Sequence Sequence
+}
+
+// AcceptsValues reports whether inserts may set the column explicitly.
+func (k *KeyGeneration) AcceptsValues() bool {
+	return k.Generation != GeneratedAlways
+}
+
+var keyOptionNames = []string{"identity", "sequence", "start", "increment", "cache", "nocache"}
+
+// keyOptions parses the oraddl tag of f into its options, e.g.
+// {"sequence": "orders_seq", "nocache": ""}.
+func keyOptions(t *schema.Table, f *schema.Field) (map[string]string, error) {
+	tag := f.StructField.Tag.Get("oraddl")
+	if tag == "" {
+		return nil, nil
+	}
+	opts := make(map[string]string)
+	for _, opt := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(opt, ":")
+		if !slices.Contains(keyOptionNames, name) {
+			return nil, fmt.Errorf("oraddl: %s.%s: unknown option %q", t.TypeName, f.GoName, name)
+		}
+		opts[name] = value
+	}
+	return opts, nil
+}
+
+// KeyGenerationOf returns the key generation of f, a field of t, which must
+// be an autoincrement or identity field.
+func KeyGenerationOf(t *schema.Table, f *schema.Field) (*KeyGeneration, error) {
+	opts, err := keyOptions(t, f)
+	if err != nil {
+		return nil, err
+	}
+	mode, hasMode := opts["identity"]
+	if !f.AutoIncrement {
+		// bun only leaves autoincrement columns out of inserts.
+		if len(opts) > 0 {
+			return nil, fmt.Errorf("oraddl: %s.%s: oraddl:%q needs autoincrement",
+				t.TypeName, f.GoName, f.StructField.Tag.Get("oraddl"))
+		}
+		if !f.Identity {
+			return nil, fmt.Errorf("oraddl: %s.%s is not an autoincrement field", t.TypeName, f.GoName)
+		}
+	}
+
+	k := &KeyGeneration{Generation: GeneratedByDefault}
+	switch mode {
+	case "", "by_default":
+	case "on_null":
+		k.Generation = GeneratedByDefaultOnNull
+	case "always":
+		k.Generation = GeneratedAlways
+	default:
+		return nil, fmt.Errorf("oraddl: %s.%s: unknown identity %q, want by_default, on_null or always", t.TypeName, f.GoName, mode)
+	}
+	if name, ok := opts["sequence"]; ok {
+		if hasMode {
+			return nil, fmt.Errorf("oraddl: %s.%s: identity and sequence exclude each other", t.TypeName, f.GoName)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("oraddl: %s.%s: sequence needs a name", t.TypeName, f.GoName)
+		}
+		k.Generation = ""
+		k.Sequence.Name = name
+	}
+
+	if k.Sequence.StartWith, err = intOption(t, f, opts, "start"); err != nil {
+		return nil, err
+	}
+	if k.Sequence.IncrementBy, err = intOption(t, f, opts, "increment"); err != nil {
+		return nil, err
+	}
+	cache, err := intOption(t, f, opts, "cache")
+	_, hasCache := opts["cache"]
+	_, noCache := opts["nocache"]
+	switch {
+	case err != nil:
+		return nil, err
+	case noCache && hasCache:
+		return nil, fmt.Errorf("oraddl: %s.%s: cache and nocache exclude each other", t.TypeName, f.GoName)
+	case noCache:
+		k.Sequence.Cache = -1
+	case hasCache && cache < 2:
+		// Oracle's minimum; a cache of 1 is spelled NOCACHE.
+		return nil, fmt.Errorf("oraddl: %s.%s: cache must be at least 2, or use nocache", t.TypeName, f.GoName)
+	default:
+		k.Sequence.Cache = int(cache)
+	}
+	return k, nil
+}
+
+func intOption(t *schema.Table, f *schema.Field, opts map[string]string, name string) (int64, error) {
+	s, ok := opts[name]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("oraddl: %s.%s: %s must be an integer, got %q", t.TypeName, f.GoName, name, s)
+	}
+	return n, nil
+}
+
+// appendKeyGeneration appends the DEFAULT or identity clause of an
+// autoincrement column.
+func appendKeyGeneration(db *bun.DB, b []byte, t *schema.Table, f *schema.Field) ([]byte, error) {
+	k, err := KeyGenerationOf(t, f)
+	if err != nil {
+		return nil, err
+	}
+	if k.Sequence.Name != "" {
+		b = append(b, " DEFAULT "...)
+		b = db.Formatter().AppendIdent(b, k.Sequence.Name)
+		return append(b, ".NEXTVAL"...), nil
+	}
+	b = append(b, " GENERATED "...)
+	b = append(b, k.Generation...)
+	b = append(b, " AS IDENTITY"...)
+	if opts := appendSequenceOptions(nil, k.Sequence); len(opts) > 0 {
+		b = append(b, " ("...)
+		b = append(b, opts[1:]...)
+		b = append(b, ')')
+	}
+	return b, nil
+}
+
+// appendSequenceOptions appends the options of seq, each after a space.
+func appendSequenceOptions(b []byte, seq Sequence) []byte {
+	if seq.StartWith != 0 {
+		b = fmt.Appendf(b, " START WITH %d", seq.StartWith)
+	}
+	if seq.IncrementBy != 0 {
+		b = fmt.Appendf(b, " INCREMENT BY %d", seq.IncrementBy)
+	}
+	switch {
+	case seq.Cache < 0:
+		b = append(b, " NOCACHE"...)
+	case seq.Cache > 0:
+		b = fmt.Appendf(b, " CACHE %d", seq.Cache)
+	}
+	return b
+}
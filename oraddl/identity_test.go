@@ -0,0 +1,107 @@
+package oraddl_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraddl"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+	"github.com/uptrace/bun/schema"
+)
+
+type (
+	byDefault struct {
+		ID int64 `bun:",pk,autoincrement"`
+	}
+	onNull struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"identity:on_null,increment:5"`
+	}
+	always struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"identity:always,nocache"`
+	}
+	sequence struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"sequence:seq,start:100,cache:20"`
+	}
+	bothModes struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"identity:always,sequence:seq"`
+	}
+	noAutoincrement struct {
+		ID int64 `bun:",pk" oraddl:"sequence:seq"`
+	}
+	badCache struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"cache:1"`
+	}
+	bothCaches struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"cache:20,nocache"`
+	}
+	unknownOption struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"sequence:seq,minvalue:1"`
+	}
+	badStart struct {
+		ID int64 `bun:",pk,autoincrement" oraddl:"start:one"`
+	}
+)
+
+func TestKeyGenerationOf(t *testing.T) {
+	tables := schema.NewTables(oracledialect.New())
+
+	tests := []struct {
+		model any
+		want  *oraddl.KeyGeneration
+		err   string
+	}{
+		{model: (*byDefault)(nil), want: &oraddl.KeyGeneration{Generation: oraddl.GeneratedByDefault}},
+		{model: (*onNull)(nil), want: &oraddl.KeyGeneration{
+			Generation: oraddl.GeneratedByDefaultOnNull, Sequence: oraddl.Sequence{IncrementBy: 5},
+		}},
+		{model: (*always)(nil), want: &oraddl.KeyGeneration{
+			Generation: oraddl.GeneratedAlways, Sequence: oraddl.Sequence{Cache: -1},
+		}},
+		{model: (*sequence)(nil), want: &oraddl.KeyGeneration{
+			Sequence: oraddl.Sequence{Name: "seq", StartWith: 100, Cache: 20},
+		}},
+		{model: (*bothModes)(nil), err: "exclude each other"},
+		{model: (*noAutoincrement)(nil), err: `oraddl:"sequence:seq" needs autoincrement`},
+		{model: (*bothCaches)(nil), err: "cache and nocache exclude each other"},
+		{model: (*unknownOption)(nil), err: `unknown option "minvalue"`},
+		{model: (*badCache)(nil), err: "at least 2"},
+		{model: (*badStart)(nil), err: "must be an integer"},
+	}
+	for _, tt := range tests {
+		table := tables.Get(reflect.TypeOf(tt.model))
+		t.Run(table.TypeName, func(t *testing.T) {
+			got, err := oraddl.KeyGenerationOf(table, table.PKs[0])
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTableIdentity(t *testing.T) {
+	db := bun.NewDB(nil, oracledialect.New())
+
+	got, err := oraddl.CreateTable(db, (*onNull)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"id" INTEGER GENERATED BY DEFAULT ON NULL AS IDENTITY (INCREMENT BY 5) NOT NULL`
+	if !strings.Contains(got, want) {
+		t.Errorf("got\n%s\nwant it to contain %s", got, want)
+	}
+
+	if _, err := oraddl.CreateTable(db, (*noAutoincrement)(nil)); err == nil {
+		t.Error("got no error for a sequence without autoincrement")
+	}
+}
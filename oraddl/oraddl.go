@@ -0,0 +1,401 @@
+// Package oraddl renders the DDL of bun models as Oracle statements, so that
+// schema changes can be reviewed as a script instead of being executed by
+// ResetModel or CreateTable.
+package oraddl
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Sequence is a sequence created before the table of a model.
+type Sequence struct {
+	Name        string
+	StartWith   int64
+	IncrementBy int64
+	// Cache is the number of cached values; 0 keeps Oracle's default of 20
+	// and -1 means NOCACHE.
+	Cache int
+}
+
+// Sequencer is implemented by models that draw values from sequences of
+// their own rather than from identity columns.
+type Sequencer interface {
+	Sequences() []Sequence
+}
+
+// Indexer is implemented by models that need indexes besides those of their
+// primary key and unique constraints.
+type Indexer interface {
+	Indexes(db *bun.DB) []*bun.CreateIndexQuery
+}
+
+type config struct {
+	tablespace      string
+	indexTablespace string
+	storage         string
+}
+
+// Option configures CreateTable, Statements and WriteScript.
+type Option func(c *config)
+
+// WithTablespace places tables in the given tablespace. The name is quoted,
+// so ordinary tablespaces must be given upper-case.
+func WithTablespace(name string) Option {
+	return func(c *config) {
+		c.tablespace = name
+	}
+}
+
+// WithIndexTablespace places indexes in the given tablespace, quoted like
+// WithTablespace.
+func WithIndexTablespace(name string) Option {
+	return func(c *config) {
+		c.indexTablespace = name
+	}
+}
+
+// WithStorage appends physical attributes such as
+// "PCTFREE 20 STORAGE (INITIAL 1M NEXT 1M)" to every CREATE TABLE verbatim.
+func WithStorage(clause string) Option {
+	return func(c *config) {
+		c.storage = clause
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := new(config)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CreateTable renders the CREATE TABLE statement of model. Unlike bun's
+// CreateTableQuery on Oracle it keeps NOT NULL, and it writes foreign keys of
+// belongs-to relations with the only rules Oracle has: ON DELETE CASCADE and
+// ON DELETE SET NULL. The unique constraints of a model with a soft_delete
+// column are left to LiveUniqueIndex.
+func CreateTable(db *bun.DB, model any, opts ...Option) (string, error) {
+	return createTable(db, db.Table(reflect.TypeOf(model)), newConfig(opts))
+}
+
+func createTable(db *bun.DB, t *schema.Table, c *config) (string, error) {
+	var b []byte
+	b = append(b, "CREATE TABLE "...)
+	b = append(b, t.SQLName...)
+	b = append(b, " (\n"...)
+
+	for i, f := range t.Fields {
+		if i > 0 {
+			b = append(b, ",\n"...)
+		}
+		b = append(b, "  "...)
+		b = append(b, f.SQLName...)
+		b = append(b, ' ')
+		b = append(b, SQLType(db.Dialect(), f)...)
+		switch {
+		case f.SQLDefault != "":
+			b = append(b, " DEFAULT "...)
+			b = append(b, f.SQLDefault...)
+		case f.AutoIncrement || f.Identity || f.StructField.Tag.Get("oraddl") != "":
+			var err error
+			if b, err = appendKeyGeneration(db, b, t, f); err != nil {
+				return "", err
+			}
+		}
+		if f.NotNull || f.IsPK {
+			b = append(b, " NOT NULL"...)
+		}
+	}
+
+	if len(t.PKs) > 0 {
+		b = append(b, ",\n  PRIMARY KEY ("...)
+		b = appendColumns(b, t.PKs)
+		b = append(b, ')')
+	}
+	if t.SoftDeleteField == nil {
+		for _, group := range uniqueGroups(t) {
+			b = append(b, ",\n  UNIQUE ("...)
+			b = appendColumns(b, group)
+			b = append(b, ')')
+		}
+	}
+	for _, rel := range foreignKeys(t) {
+		if rel.OnUpdate != "ON UPDATE NO ACTION" {
+			return "", fmt.Errorf("oraddl: %s.%s: Oracle does not support %s",
+				t.TypeName, rel.Field.GoName, rel.OnUpdate)
+		}
+		b = append(b, ",\n  FOREIGN KEY ("...)
+		b = appendColumns(b, rel.BasePKs)
+		b = append(b, ") REFERENCES "...)
+		b = append(b, rel.JoinTable.SQLName...)
+		b = append(b, " ("...)
+		b = appendColumns(b, rel.JoinPKs)
+		b = append(b, ')')
+		switch rel.OnDelete {
+		case "ON DELETE CASCADE", "ON DELETE SET NULL":
+			b = append(b, ' ')
+			b = append(b, rel.OnDelete...)
+		case "ON DELETE NO ACTION", "ON DELETE RESTRICT":
+			// Oracle's behaviour when no rule is given.
+		default:
+			return "", fmt.Errorf("oraddl: %s.%s: Oracle does not support %s",
+				t.TypeName, rel.Field.GoName, rel.OnDelete)
+		}
+	}
+	b = append(b, "\n)"...)
+
+	if c.tablespace != "" {
+		b = append(b, " TABLESPACE "...)
+		b = db.Formatter().AppendIdent(b, c.tablespace)
+	}
+	if c.storage != "" {
+		b = append(b, ' ')
+		b = append(b, c.storage...)
+	}
+	return string(b), nil
+}
+
+// defaultTypes are the SQL types of the oranum and oratime types when their
+// tag has none; bun would make them VARCHAR2.
+var defaultTypes = map[reflect.Type]string{
+	reflect.TypeOf(oranum.Decimal{}):   "NUMBER",
+	reflect.TypeOf(oratime.Time{}):     "TIMESTAMP WITH TIME ZONE",
+	reflect.TypeOf(oratime.Duration{}): "INTERVAL DAY(9) TO SECOND(6)",
+}
+
+// SQLType returns the type CreateTable writes for f: the one bun would use,
+// with plain varchar replaced by VARCHAR2 of the dialect's default length.
+// A Decimal, Time or Duration without a type in its tag gets its entry in
+// defaultTypes.
+func SQLType(d schema.Dialect, f *schema.Field) string {
+	if _, ok := f.Tag.Option("type"); !ok && defaultTypes[f.IndirectType] != "" {
+		return defaultTypes[f.IndirectType]
+	}
+	typ := f.CreateTableSQLType
+	if strings.EqualFold(typ, f.DiscoveredSQLType) && strings.EqualFold(typ, "varchar") {
+		return fmt.Sprintf("VARCHAR2(%d)", d.DefaultVarcharLen())
+	}
+	return typ
+}
+
+func appendColumns(b []byte, fields []*schema.Field) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, f.SQLName...)
+	}
+	return b
+}
+
+// uniqueGroups returns the column groups of the unique constraints of t in
+// a stable order: fields tagged plain "unique" each form a group of their own.
+func uniqueGroups(t *schema.Table) [][]*schema.Field {
+	var groups [][]*schema.Field
+	for _, f := range t.Unique[""] {
+		groups = append(groups, []*schema.Field{f})
+	}
+	for _, key := range sortedKeys(t.Unique) {
+		if key != "" {
+			groups = append(groups, t.Unique[key])
+		}
+	}
+	return groups
+}
+
+// LiveUniqueIndexName is the name of the index LiveUniqueIndex creates.
+func LiveUniqueIndexName(t *schema.Table, fields []*schema.Field) string {
+	name := t.Name
+	for _, f := range fields {
+		name += "_" + f.Name
+	}
+	return name + "_uq"
+}
+
+// LiveUniqueIndex renders a unique constraint on fields of t, a model with a
+// soft_delete column, as a function-based unique index that only covers live
+// rows. Oracle leaves rows whose indexed expressions are all NULL out of an
+// index, so deleted rows never conflict:
+//
+//	CREATE UNIQUE INDEX "users_email_uq" ON "users" (CASE WHEN "deleted_at" IS NULL THEN "email" END)
+func LiveUniqueIndex(db *bun.DB, t *schema.Table, fields []*schema.Field) string {
+	b := []byte("CREATE UNIQUE INDEX ")
+	b = db.Formatter().AppendIdent(b, LiveUniqueIndexName(t, fields))
+	b = append(b, " ON "...)
+	b = append(b, t.SQLName...)
+	b = append(b, " ("...)
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, "CASE WHEN "...)
+		b = append(b, t.SoftDeleteField.SQLName...)
+		b = append(b, " IS NULL THEN "...)
+		b = append(b, f.SQLName...)
+		b = append(b, " END"...)
+	}
+	b = append(b, ')')
+	return string(b)
+}
+
+// foreignKeys returns the belongs-to relations of t, ordered by field.
+func foreignKeys(t *schema.Table) []*schema.Relation {
+	var rels []*schema.Relation
+	for _, name := range sortedKeys(t.Relations) {
+		if rel := t.Relations[name]; rel.Type == schema.BelongsToRelation {
+			rels = append(rels, rel)
+		}
+	}
+	return rels
+}
+
+// CreateSequence renders the CREATE SEQUENCE statement of seq.
+func CreateSequence(db *bun.DB, seq Sequence) string {
+	b := []byte("CREATE SEQUENCE ")
+	b = db.Formatter().AppendIdent(b, seq.Name)
+	return string(appendSequenceOptions(b, seq))
+}
+
+// Statements renders the DDL of models: for each model its sequences, both
+// those of Sequencer and those of key columns tagged with a sequence (see
+// KeyGeneration), its table and its indexes, including the live unique
+// indexes of soft-deleted models. Models are ordered so that every table
+// comes after the tables its foreign keys reference, keeping the given order
+// otherwise.
+func Statements(db *bun.DB, models []any, opts ...Option) ([]string, error) {
+	c := newConfig(opts)
+	ordered, err := dependencyOrder(db, models)
+	if err != nil {
+		return nil, err
+	}
+
+	var stmts []string
+	for _, model := range ordered {
+		t := db.Table(reflect.TypeOf(model))
+
+		if s, ok := model.(Sequencer); ok {
+			for _, seq := range s.Sequences() {
+				stmts = append(stmts, CreateSequence(db, seq))
+			}
+		}
+		for _, f := range t.Fields {
+			if !f.AutoIncrement {
+				continue
+			}
+			k, err := KeyGenerationOf(t, f)
+			if err != nil {
+				return nil, err
+			}
+			if k.Sequence.Name != "" {
+				stmts = append(stmts, CreateSequence(db, k.Sequence))
+			}
+		}
+
+		stmt, err := createTable(db, t, c)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+
+		var indexes [][]byte
+		if t.SoftDeleteField != nil {
+			for _, group := range uniqueGroups(t) {
+				indexes = append(indexes, []byte(LiveUniqueIndex(db, t, group)))
+			}
+		}
+		if ix, ok := model.(Indexer); ok {
+			for _, q := range ix.Indexes(db) {
+				b, err := q.AppendQuery(db.Formatter(), nil)
+				if err != nil {
+					return nil, fmt.Errorf("oraddl: %s index: %w", t.TypeName, err)
+				}
+				indexes = append(indexes, b)
+			}
+		}
+		for _, b := range indexes {
+			if c.indexTablespace != "" {
+				b = append(b, " TABLESPACE "...)
+				b = db.Formatter().AppendIdent(b, c.indexTablespace)
+			}
+			stmts = append(stmts, string(b))
+		}
+	}
+	return stmts, nil
+}
+
+// WriteScript writes the statements of Statements as a SQL script that
+// SQL*Plus, SQLcl and oramigrate.SplitStatements can run.
+func WriteScript(w io.Writer, db *bun.DB, models []any, opts ...Option) error {
+	stmts, err := Statements(db, models, opts...)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "-- Generated by the ddl command from the registered bun models.\n"); err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := fmt.Fprintf(w, "\n%s;\n", stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dependencyOrder(db *bun.DB, models []any) ([]any, error) {
+	tables := make([]*schema.Table, len(models))
+	index := make(map[*schema.Table]int, len(models))
+	for i, model := range models {
+		tables[i] = db.Table(reflect.TypeOf(model))
+		index[tables[i]] = i
+	}
+
+	done := make([]bool, len(models))
+	ordered := make([]any, 0, len(models))
+	for len(ordered) < len(models) {
+		next := slices.IndexFunc(tables, func(t *schema.Table) bool {
+			return !done[index[t]] && depsDone(t, index, done)
+		})
+		if next < 0 {
+			var cycle []string
+			for i, t := range tables {
+				if !done[i] {
+					cycle = append(cycle, t.TypeName)
+				}
+			}
+			return nil, fmt.Errorf("oraddl: foreign keys form a cycle between %s",
+				strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		ordered = append(ordered, models[next])
+	}
+	return ordered, nil
+}
+
+func depsDone(t *schema.Table, index map[*schema.Table]int, done []bool) bool {
+	for _, rel := range foreignKeys(t) {
+		// Tables outside models are assumed to exist already.
+		if i, ok := index[rel.JoinTable]; ok && rel.JoinTable != t && !done[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
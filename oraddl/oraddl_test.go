@@ -0,0 +1,47 @@
+package oraddl_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraddl"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+type user struct {
+	bun.BaseModel `bun:"table:users"`
+
+	ID        int64     `bun:",pk"`
+	Email     string    `bun:",unique"`
+	Tenant    int64     `bun:",unique:tenant_login"`
+	Login     string    `bun:",unique:tenant_login"`
+	DeletedAt time.Time `bun:",soft_delete,nullzero"`
+}
+
+func TestStatementsSoftDelete(t *testing.T) {
+	db := bun.NewDB(nil, oracledialect.New())
+
+	stmts, err := oraddl.Statements(db, []any{(*user)(nil)}, oraddl.WithIndexTablespace("idx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unique constraints cover live rows only, so they become indexes.
+	want := []string{
+		`CREATE TABLE "users" (
+  "id" INTEGER NOT NULL,
+  "email" VARCHAR2(255),
+  "tenant" INTEGER,
+  "login" VARCHAR2(255),
+  "deleted_at" TIMESTAMP,
+  PRIMARY KEY ("id")
+)`,
+		`CREATE UNIQUE INDEX "users_email_uq" ON "users" (CASE WHEN "deleted_at" IS NULL THEN "email" END) TABLESPACE "idx"`,
+		`CREATE UNIQUE INDEX "users_tenant_login_uq" ON "users" ` +
+			`(CASE WHEN "deleted_at" IS NULL THEN "tenant" END, CASE WHEN "deleted_at" IS NULL THEN "login" END) TABLESPACE "idx"`,
+	}
+	if !slices.Equal(stmts, want) {
+		t.Errorf("got\n%s\nwant\n%s", stmts, want)
+	}
+}
@@ -0,0 +1,159 @@
+// Package oraerr classifies errors returned by the go-ora driver so that code
+// built on top of bun can branch on the kind of failure instead of matching
+// error strings.
+package oraerr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/sijms/go-ora/v2/network"
+)
+
+// ORA error codes recognised by this package.
+const (
+	CodeUniqueViolation      = 1
+	CodeDeadlock             = 60
+	CodeResourceBusy         = 54
+	CodeNoDataFound          = 1403
+	CodeEndOfFileOnChannel   = 3113
+	CodeNotConnected         = 3114
+	CodeConnectionLost       = 3135
+	CodeSerializationFailure = 8177
+	CodeNoListener           = 12541
+)
+
+// Sentinel errors describing the kind of a classified failure. They can be
+// matched with errors.Is against any error returned by Classify.
+var (
+	ErrUniqueViolation      = errors.New("oraerr: unique constraint violated")
+	ErrNotFound             = errors.New("oraerr: not found")
+	ErrDeadlock             = errors.New("oraerr: deadlock detected")
+	ErrSerializationFailure = errors.New("oraerr: cannot serialize access")
+	ErrConnectionLost       = errors.New("oraerr: connection lost")
+	ErrResourceBusy         = errors.New("oraerr: resource busy")
+)
+
+var kinds = map[int]error{
+	CodeUniqueViolation:      ErrUniqueViolation,
+	CodeDeadlock:             ErrDeadlock,
+	CodeResourceBusy:         ErrResourceBusy,
+	CodeNoDataFound:          ErrNotFound,
+	CodeEndOfFileOnChannel:   ErrConnectionLost,
+	CodeNotConnected:         ErrConnectionLost,
+	CodeConnectionLost:       ErrConnectionLost,
+	CodeSerializationFailure: ErrSerializationFailure,
+	CodeNoListener:           ErrConnectionLost,
+}
+
+// Error is a driver error annotated with its ORA code and kind.
+type Error struct {
+	// Code is the ORA error number, or 0 if the error did not come from the
+	// database server.
+	Code int
+	// Kind is one of the sentinel errors of this package.
+	Kind error
+
+	err error
+}
+
+func (e *Error) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("%v: %v", e.Kind, e.err)
+	}
+	return fmt.Sprintf("%v (ORA-%05d): %v", e.Kind, e.Code, e.err)
+}
+
+// Is reports whether target is the kind of e.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Code returns the ORA error number carried by err, if any.
+func Code(err error) (int, bool) {
+	var oraErr *network.OracleError
+	if errors.As(err, &oraErr) {
+		return oraErr.ErrCode, true
+	}
+	return 0, false
+}
+
+// Classify wraps err in an *Error when it is of a known kind, otherwise it
+// returns err unchanged. A nil error stays nil.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	if kind := kindOf(err); kind != nil {
+		code, _ := Code(err)
+		return &Error{Code: code, Kind: kind, err: err}
+	}
+	return err
+}
+
+func kindOf(err error) error {
+	if code, ok := Code(err); ok {
+		return kinds[code]
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, network.ErrConnReset):
+		return ErrConnectionLost
+	}
+	return nil
+}
+
+func is(err, kind error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, kind) || kindOf(err) == kind
+}
+
+// IsUniqueViolation reports whether err is ORA-00001.
+func IsUniqueViolation(err error) bool {
+	return is(err, ErrUniqueViolation)
+}
+
+// IsNotFound reports whether err is sql.ErrNoRows or ORA-01403.
+func IsNotFound(err error) bool {
+	return is(err, ErrNotFound)
+}
+
+// IsDeadlock reports whether err is ORA-00060.
+func IsDeadlock(err error) bool {
+	return is(err, ErrDeadlock)
+}
+
+// IsSerializationFailure reports whether err is ORA-08177.
+func IsSerializationFailure(err error) bool {
+	return is(err, ErrSerializationFailure)
+}
+
+// IsConnectionLost reports whether err means the session to the server is
+// gone (ORA-03113, ORA-03114, ORA-03135, ORA-12541 or a bad connection).
+func IsConnectionLost(err error) bool {
+	return is(err, ErrConnectionLost)
+}
+
+// IsResourceBusy reports whether err is ORA-00054.
+func IsResourceBusy(err error) bool {
+	return is(err, ErrResourceBusy)
+}
+
+// IsRetryable reports whether the operation that produced err may succeed
+// if it is run again from the start.
+func IsRetryable(err error) bool {
+	return IsDeadlock(err) || IsSerializationFailure(err) || IsConnectionLost(err)
+}
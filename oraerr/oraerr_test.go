@@ -0,0 +1,114 @@
+package oraerr_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/sijms/go-ora/v2/network"
+)
+
+func oraError(code int) error {
+	return &network.OracleError{ErrCode: code, ErrMsg: fmt.Sprintf("ORA-%05d: test", code)}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error // nil when the error is left unclassified
+		code int
+	}{
+		{"ORA-00001", oraError(1), oraerr.ErrUniqueViolation, 1},
+		{"ORA-00054", oraError(54), oraerr.ErrResourceBusy, 54},
+		{"ORA-00060", oraError(60), oraerr.ErrDeadlock, 60},
+		{"ORA-01403", oraError(1403), oraerr.ErrNotFound, 1403},
+		{"ORA-03113", oraError(3113), oraerr.ErrConnectionLost, 3113},
+		{"ORA-03114", oraError(3114), oraerr.ErrConnectionLost, 3114},
+		{"ORA-03135", oraError(3135), oraerr.ErrConnectionLost, 3135},
+		{"ORA-08177", oraError(8177), oraerr.ErrSerializationFailure, 8177},
+		{"ORA-12541", oraError(12541), oraerr.ErrConnectionLost, 12541},
+		{"ORA-00942", oraError(942), nil, 0},
+		{"sql.ErrNoRows", sql.ErrNoRows, oraerr.ErrNotFound, 0},
+		{"bad connection", driver.ErrBadConn, oraerr.ErrConnectionLost, 0},
+		{"connection reset", network.ErrConnReset, oraerr.ErrConnectionLost, 0},
+		{"other", errors.New("boom"), nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := oraerr.Classify(tt.err)
+			var classified *oraerr.Error
+			if !errors.As(got, &classified) {
+				if tt.kind != nil {
+					t.Fatalf("got %v, want kind %v", got, tt.kind)
+				}
+				if got != tt.err {
+					t.Errorf("got %v, want the error unchanged", got)
+				}
+				return
+			}
+			if classified.Kind != tt.kind || classified.Code != tt.code {
+				t.Errorf("got kind %v code %d, want %v code %d", classified.Kind, classified.Code, tt.kind, tt.code)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("got %v, want it to wrap %v", got, tt.err)
+			}
+		})
+	}
+
+	if oraerr.Classify(nil) != nil {
+		t.Error("Classify(nil) is not nil")
+	}
+	once := oraerr.Classify(oraError(1))
+	if twice := oraerr.Classify(once); twice != once {
+		t.Errorf("got %v, want a classified error unchanged", twice)
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	tests := []struct {
+		name string
+		is   func(error) bool
+		err  error
+		want bool
+	}{
+		{"IsUniqueViolation", oraerr.IsUniqueViolation, oraError(1), true},
+		{"IsUniqueViolation", oraerr.IsUniqueViolation, oraError(60), false},
+		{"IsNotFound", oraerr.IsNotFound, sql.ErrNoRows, true},
+		{"IsNotFound", oraerr.IsNotFound, oraError(1403), true},
+		{"IsDeadlock", oraerr.IsDeadlock, oraError(60), true},
+		{"IsSerializationFailure", oraerr.IsSerializationFailure, oraError(8177), true},
+		{"IsConnectionLost", oraerr.IsConnectionLost, oraError(3135), true},
+		{"IsConnectionLost", oraerr.IsConnectionLost, driver.ErrBadConn, true},
+		{"IsResourceBusy", oraerr.IsResourceBusy, oraError(54), true},
+		{"IsRetryable", oraerr.IsRetryable, oraError(60), true},
+		{"IsRetryable", oraerr.IsRetryable, oraError(8177), true},
+		{"IsRetryable", oraerr.IsRetryable, oraError(3135), true},
+		{"IsRetryable", oraerr.IsRetryable, oraError(1), false},
+		{"IsRetryable", oraerr.IsRetryable, nil, false},
+	}
+	for _, tt := range tests {
+		if got := tt.is(tt.err); got != tt.want {
+			t.Errorf("%s(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWrapped(t *testing.T) {
+	// Raw and classified driver errors still match once wrapped by callers.
+	for _, err := range []error{oraError(60), oraerr.Classify(oraError(60))} {
+		wrapped := fmt.Errorf("update products: %w", err)
+		if !errors.Is(oraerr.Classify(wrapped), oraerr.ErrDeadlock) {
+			t.Errorf("Classify(%v) does not match ErrDeadlock", wrapped)
+		}
+		if !oraerr.IsDeadlock(wrapped) {
+			t.Errorf("IsDeadlock(%v) = false", wrapped)
+		}
+		if code, ok := oraerr.Code(wrapped); !ok || code != 60 {
+			t.Errorf("Code(%v) = %d, %v, want 60", wrapped, code, ok)
+		}
+	}
+}
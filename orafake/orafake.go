@@ -0,0 +1,368 @@
+// Package orafake is a database/sql driver that records statements instead
+// of sending them to Oracle, for unit tests of code built on bun with
+// oracledialect. Results and errors, including ORA errors, are scripted per
+// statement pattern.
+package orafake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sijms/go-ora/v2/network"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+// DriverName is the name the driver is registered under. Its data source
+// name is the DSN of a Recorder.
+const DriverName = "orafake"
+
+// Transaction boundaries are recorded, and can be scripted, as statements
+// with these texts.
+const (
+	Begin    = "BEGIN"
+	Commit   = "COMMIT"
+	Rollback = "ROLLBACK"
+)
+
+func init() {
+	sql.Register(DriverName, Driver{})
+}
+
+var (
+	recordersMu sync.Mutex
+	recorders   = make(map[string]*Recorder)
+	lastID      atomic.Int64
+)
+
+// Driver opens connections to the Recorder named by the data source name.
+type Driver struct{}
+
+func (Driver) Open(name string) (driver.Conn, error) {
+	c, err := Driver{}.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+func (Driver) OpenConnector(name string) (driver.Connector, error) {
+	recordersMu.Lock()
+	r := recorders[name]
+	recordersMu.Unlock()
+	if r == nil {
+		return nil, fmt.Errorf("orafake: unknown recorder %q", name)
+	}
+	return r, nil
+}
+
+// Call is a statement received by the driver, with its bound arguments.
+type Call struct {
+	Query string
+	Args  []any
+}
+
+// Recorder records the statements of its connections and answers them from
+// its rules. It is a driver.Connector; all its connections share one log.
+type Recorder struct {
+	dsn string
+
+	mu    sync.Mutex
+	calls []Call
+	rules []*Rule
+}
+
+// New returns a Recorder registered with the driver under a unique DSN.
+func New() *Recorder {
+	r := &Recorder{dsn: "recorder-" + strconv.FormatInt(lastID.Add(1), 10)}
+	recordersMu.Lock()
+	recorders[r.dsn] = r
+	recordersMu.Unlock()
+	return r
+}
+
+// DSN returns the data source name to pass to sql.Open with DriverName.
+func (r *Recorder) DSN() string {
+	return r.dsn
+}
+
+// Close unregisters r. Open databases keep working.
+func (r *Recorder) Close() {
+	recordersMu.Lock()
+	delete(recorders, r.dsn)
+	recordersMu.Unlock()
+}
+
+// DB returns a bun.DB with oracledialect backed by r.
+func (r *Recorder) DB() *bun.DB {
+	return bun.NewDB(sql.OpenDB(r), oracledialect.New())
+}
+
+func (r *Recorder) Connect(context.Context) (driver.Conn, error) {
+	return &conn{r: r}, nil
+}
+
+func (r *Recorder) Driver() driver.Driver {
+	return Driver{}
+}
+
+// Calls returns the statements recorded so far.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+// Queries returns the text of the statements recorded so far.
+func (r *Recorder) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queries := make([]string, len(r.calls))
+	for i, c := range r.calls {
+		queries[i] = c.Query
+	}
+	return queries
+}
+
+// Reset forgets the recorded statements but keeps the rules.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.calls = nil
+	r.mu.Unlock()
+}
+
+// On adds a rule for statements matching the regular expression pattern.
+// Rules are tried in the order they were added and the first one that
+// matches and is not used up answers. A statement no rule answers succeeds:
+// an Exec affects no rows and a Query returns no rows.
+func (r *Recorder) On(pattern string) *Rule {
+	rule := &Rule{re: regexp.MustCompile(pattern)}
+	r.mu.Lock()
+	r.rules = append(r.rules, rule)
+	r.mu.Unlock()
+	return rule
+}
+
+// record logs a statement and returns the rule answering it, if any.
+func (r *Recorder) record(query string, args []driver.NamedValue) *Rule {
+	call := Call{Query: query}
+	for _, a := range args {
+		call.Args = append(call.Args, a.Value)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	for _, rule := range r.rules {
+		if (rule.times == 0 || rule.used < rule.times) && rule.re.MatchString(query) {
+			rule.used++
+			return rule
+		}
+	}
+	return nil
+}
+
+// Rule scripts the answer to matching statements.
+type Rule struct {
+	re       *regexp.Regexp
+	times    int
+	used     int
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// Return makes a Query return rows with the given columns. Values are
+// converted as database/sql converts arguments, so ints, strings, []byte,
+// time.Time and nil can be used directly.
+func (rule *Rule) Return(columns []string, rows ...[]any) *Rule {
+	rule.columns = columns
+	rule.rows = make([][]driver.Value, len(rows))
+	for i, row := range rows {
+		rule.rows[i] = make([]driver.Value, len(row))
+		for j, v := range row {
+			dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				panic(fmt.Errorf("orafake: row %d column %d: %w", i, j, err))
+			}
+			rule.rows[i][j] = dv
+		}
+	}
+	return rule
+}
+
+// Affect makes an Exec report n affected rows.
+func (rule *Rule) Affect(n int64) *Rule {
+	rule.affected = n
+	return rule
+}
+
+// Fail makes the statement fail with err; see OraError.
+func (rule *Rule) Fail(err error) *Rule {
+	rule.err = err
+	return rule
+}
+
+// Times limits the rule to the first n matching statements.
+func (rule *Rule) Times(n int) *Rule {
+	rule.times = n
+	return rule
+}
+
+// OraError returns the error go-ora returns for ORA-<code>, so that the
+// oraerr predicates classify it as they would a real one.
+func OraError(code int, msg string) error {
+	return &network.OracleError{ErrCode: code, ErrMsg: fmt.Sprintf("ORA-%05d: %s", code, msg)}
+}
+
+type conn struct {
+	r *Recorder
+}
+
+var (
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	if rule := c.r.record(Begin, nil); rule != nil && rule.err != nil {
+		return nil, rule.err
+	}
+	return &tx{c: c}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rule := c.r.record(query, args)
+	if rule == nil {
+		return result(0), nil
+	}
+	if rule.err != nil {
+		return nil, rule.err
+	}
+	return result(rule.affected), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rule := c.r.record(query, args)
+	if rule == nil {
+		return &rows{}, nil
+	}
+	if rule.err != nil {
+		return nil, rule.err
+	}
+	return &rows{columns: rule.columns, values: rule.rows}, nil
+}
+
+// CheckNamedValue accepts every argument as is, like go-ora does for the
+// types it binds natively.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+type stmt struct {
+	c     *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nv
+}
+
+type tx struct {
+	c *conn
+}
+
+func (t *tx) Commit() error {
+	if rule := t.c.r.record(Commit, nil); rule != nil {
+		return rule.err
+	}
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	if rule := t.c.r.record(Rollback, nil); rule != nil {
+		return rule.err
+	}
+	return nil
+}
+
+type result int64
+
+func (r result) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
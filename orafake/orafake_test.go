@@ -0,0 +1,164 @@
+package orafake_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+)
+
+func open(t *testing.T) (*sql.DB, *orafake.Recorder) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db, err := sql.Open(orafake.DriverName, rec.DSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func affected(t *testing.T, db *sql.DB, query string) int64 {
+	t.Helper()
+	res, err := db.Exec(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestRuleOrder(t *testing.T) {
+	db, rec := open(t)
+	rec.On(`^UPDATE products`).Times(2).Affect(1)
+	rec.On(`^UPDATE`).Affect(5)
+	// Never reached: the rule above matches every UPDATE.
+	rec.On(`^UPDATE products`).Affect(9)
+
+	var got []int64
+	for range 4 {
+		got = append(got, affected(t, db, "UPDATE products SET price = 1"))
+	}
+	got = append(got, affected(t, db, "UPDATE orders SET total = 1"))
+	if want := []int64{1, 1, 5, 5, 5}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReturn(t *testing.T) {
+	db, rec := open(t)
+	rec.On(`^SELECT`).Times(1).Return([]string{"id", "name"}, []any{1, "pear"}, []any{2, nil})
+
+	rows, err := db.Query("SELECT id, name FROM products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	cols, _ := rows.Columns()
+	if want := []string{"id", "name"}; !slices.Equal(cols, want) {
+		t.Errorf("got columns %q, want %q", cols, want)
+	}
+	type row struct {
+		ID   int64
+		Name sql.NullString
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.ID, &r.Name); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []row{{1, sql.NullString{String: "pear", Valid: true}}, {2, sql.NullString{}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// The rule is used up: the same query now finds no rows.
+	var id int64
+	if err := db.QueryRow("SELECT id, name FROM products").Scan(&id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestFail(t *testing.T) {
+	db, rec := open(t)
+	rec.On(`^INSERT`).Fail(orafake.OraError(oraerr.CodeUniqueViolation, "unique constraint violated"))
+	rec.On(`^SELECT`).Fail(orafake.OraError(oraerr.CodeDeadlock, "deadlock detected"))
+
+	if _, err := db.Exec("INSERT INTO products (name) VALUES ('pear')"); !oraerr.IsUniqueViolation(err) {
+		t.Errorf("got %v, want a unique violation", err)
+	}
+	if _, err := db.Query("SELECT 1 FROM dual"); !oraerr.IsDeadlock(err) {
+		t.Errorf("got %v, want a deadlock", err)
+	}
+}
+
+func TestFailBegin(t *testing.T) {
+	db, rec := open(t)
+	rec.On(orafake.Begin).Fail(orafake.OraError(oraerr.CodeEndOfFileOnChannel, "end-of-file on communication channel"))
+
+	if _, err := db.BeginTx(context.Background(), nil); !oraerr.IsConnectionLost(err) {
+		t.Errorf("got %v, want a lost connection", err)
+	}
+}
+
+func TestCalls(t *testing.T) {
+	db, rec := open(t)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("UPDATE products SET name = :1 WHERE id = :2", "pear", 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []orafake.Call{
+		{Query: orafake.Begin},
+		{Query: "UPDATE products SET name = :1 WHERE id = :2", Args: []any{"pear", 7}},
+		{Query: orafake.Commit},
+	}
+	if got := rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	rec.Reset()
+	if got := rec.Queries(); len(got) != 0 {
+		t.Errorf("got %q after Reset, want none", got)
+	}
+}
+
+func TestNoRule(t *testing.T) {
+	db, _ := open(t)
+
+	if n := affected(t, db, "DELETE FROM products"); n != 0 {
+		t.Errorf("got %d rows affected, want 0", n)
+	}
+	rows, err := db.Query("SELECT id FROM products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		t.Error("got a row, want none")
+	}
+	if err := rows.Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,75 @@
+package oragen
+
+import (
+	"strings"
+	"unicode"
+)
+
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName turns an Oracle identifier such as ORDER_ITEM_ID into an exported
+// Go name such as OrderItemID.
+func goName(ident string) string {
+	words := strings.FieldsFunc(ident, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		if initialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		lower := []rune(strings.ToLower(w))
+		lower[0] = unicode.ToUpper(lower[0])
+		sb.WriteString(string(lower))
+	}
+
+	name := sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// singular strips a plural suffix from the last word of a table name, so
+// that ORDER_ITEMS becomes ORDER_ITEM and CATEGORIES becomes CATEGORY.
+func singular(name string) string {
+	upper := strings.ToUpper(name)
+	switch {
+	case strings.HasSuffix(upper, "IES") && len(name) > 3:
+		return name[:len(name)-3] + matchCase(name, "y")
+	case strings.HasSuffix(upper, "SSES"), strings.HasSuffix(upper, "XES"),
+		strings.HasSuffix(upper, "CHES"), strings.HasSuffix(upper, "SHES"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(upper, "S") && !strings.HasSuffix(upper, "SS") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+func matchCase(ref, s string) string {
+	if strings.ToUpper(ref) == ref {
+		return strings.ToUpper(s)
+	}
+	return s
+}
+
+// alias builds a short lower-case table alias from the initials of the
+// words of name.
+func alias(name string) string {
+	var sb strings.Builder
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		sb.WriteRune(unicode.ToLower([]rune(w)[0]))
+	}
+	if sb.Len() == 0 || !unicode.IsLetter([]rune(sb.String())[0]) {
+		return "t" + sb.String()
+	}
+	return sb.String()
+}
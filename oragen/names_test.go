@@ -0,0 +1,64 @@
+package oragen
+
+import "testing"
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"ORDER_ITEM_ID": "OrderItemID",
+		"order_items":   "OrderItems",
+		"API_URL":       "APIURL",
+		"USER$NAME":     "UserName",
+		"ADDRESS2":      "Address2",
+		"2FA_CODE":      "X2faCode",
+		"__":            "X",
+	}
+	for ident, want := range tests {
+		if got := goName(ident); got != want {
+			t.Errorf("goName(%q) = %q, want %q", ident, got, want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"ORDER_ITEMS": "ORDER_ITEM",
+		"CATEGORIES":  "CATEGORY",
+		"categories":  "category",
+		"ADDRESSES":   "ADDRESS",
+		"BOXES":       "BOX",
+		"BATCHES":     "BATCH",
+		"WISHES":      "WISH",
+		"ADDRESS":     "ADDRESS",
+		"STATUS":      "STATU",
+		"S":           "S",
+		"PERSON":      "PERSON",
+	}
+	for name, want := range tests {
+		if got := singular(name); got != want {
+			t.Errorf("singular(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAlias(t *testing.T) {
+	tests := map[string]string{
+		"ORDER_ITEMS": "oi",
+		"customers":   "c",
+		"T$AUDIT_LOG": "tal",
+		"2024_SALES":  "t2s",
+	}
+	for name, want := range tests {
+		if got := alias(name); got != want {
+			t.Errorf("alias(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestUnique(t *testing.T) {
+	used := map[string]bool{}
+	for _, want := range []string{"Order", "Order2", "Order3"} {
+		if got := unique(used, "Order"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
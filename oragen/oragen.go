@@ -0,0 +1,264 @@
+// Package oragen generates bun models from tables read with oraschema.
+package oragen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraschema"
+)
+
+type model struct {
+	Name   string
+	Table  *oraschema.Table
+	Alias  string
+	Fields []field
+}
+
+type field struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// Generate writes a Go file of package pkg with one struct per table.
+// Columns map to fields with bun tags for the column name, primary key,
+// identity, nullability and SQL type. Foreign keys between the given tables
+// become belongs-to relations on the referencing model and has-one or
+// has-many relations on the referenced one, depending on whether the foreign
+// key columns are unique. Note that bun v1.2 loads belongs-to and has-one
+// relations with "LEFT JOIN t AS alias", which Oracle rejects; has-many
+// relations are loaded with a separate query and work.
+func Generate(w io.Writer, pkg string, tables map[string]*oraschema.Table) error {
+	names := oraschema.SortedNames(tables)
+	models := make(map[string]*model, len(names))
+	usedNames := make(map[string]bool)
+	usedAliases := make(map[string]bool)
+	for _, name := range names {
+		t := tables[name]
+		m := &model{
+			Name:  unique(usedNames, goName(singular(t.Name))),
+			Table: t,
+			Alias: unique(usedAliases, alias(t.Name)),
+		}
+		for _, c := range t.Columns {
+			m.Fields = append(m.Fields, columnField(t, c))
+		}
+		models[name] = m
+	}
+
+	// Relation fields are named after columns and tables, so they may
+	// collide with column fields and with each other.
+	fieldNames := make(map[string]map[string]bool, len(models))
+	for name, m := range models {
+		fieldNames[name] = make(map[string]bool, len(m.Fields))
+		for _, f := range m.Fields {
+			fieldNames[name][f.Name] = true
+		}
+	}
+	for _, name := range names {
+		child := models[name]
+		for _, fk := range child.Table.ForeignKeys {
+			parent := models[fk.RefTable]
+			if parent == nil || fk.RefOwner != parent.Table.Owner {
+				continue
+			}
+
+			child.Fields = append(child.Fields, field{
+				Name: unique(fieldNames[name], belongsToName(fk, parent)),
+				Type: "*" + parent.Name,
+				Tag:  "rel:belongs-to," + joinTag(fk.Columns, fk.RefColumns),
+			})
+
+			rel, typ, fname := "has-many", "[]*"+child.Name, goName(child.Table.Name)
+			if child.Table.HasUniqueIndex(fk.Columns) {
+				rel, typ, fname = "has-one", "*"+child.Name, child.Name
+			}
+			parent.Fields = append(parent.Fields, field{
+				Name: unique(fieldNames[fk.RefTable], fname),
+				Type: typ,
+				Tag:  "rel:" + rel + "," + joinTag(fk.RefColumns, fk.Columns),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Generated by genmodels from the Oracle data dictionary.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	writeImports(&buf, models)
+	for _, name := range names {
+		m := models[name]
+		fmt.Fprintf(&buf, "\ntype %s struct {\n", m.Name)
+		fmt.Fprintf(&buf, "\tbun.BaseModel `bun:\"table:%s,alias:%s\"`\n\n", m.Table.Name, m.Alias)
+		for _, f := range m.Fields {
+			fmt.Fprintf(&buf, "\t%s %s `bun:%s`\n", f.Name, f.Type, strconv.Quote(f.Tag))
+		}
+		buf.WriteString("}\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("oragen: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func columnField(t *oraschema.Table, c *oraschema.Column) field {
+	typ, sqlType := goType(c)
+
+	tag := []string{c.Name}
+	isPK := len(t.PrimaryKey) > 0 && contains(t.PrimaryKey, c.Name)
+	if isPK {
+		tag = append(tag, "pk")
+	}
+	if c.Identity {
+		tag = append(tag, "autoincrement")
+	}
+	switch {
+	case c.Nullable:
+		tag = append(tag, "nullzero")
+	case !isPK:
+		tag = append(tag, "notnull")
+	}
+	if sqlType != "" {
+		tag = append(tag, "type:"+sqlType)
+	}
+
+	return field{Name: goName(c.Name), Type: typ, Tag: strings.Join(tag, ",")}
+}
+
+// goType returns the Go type of c and, when bun would not pick the same SQL
+// type for it, the type to put in the tag.
+func goType(c *oraschema.Column) (string, string) {
+	dictType := strings.ToLower(c.DictionaryType())
+	switch c.DataType {
+	case "NUMBER":
+		switch {
+		case c.Scale == nil && c.Precision == nil:
+			return "oranum.Decimal", "number"
+		case c.Scale != nil && *c.Scale != 0:
+			return "oranum.Decimal", dictType
+		case c.Precision == nil:
+			// INTEGER, which is what bun creates for int64.
+			return "int64", ""
+		case *c.Precision <= 18:
+			return "int64", dictType
+		}
+		// Wider than int64; go-ora scans NUMBER into strings exactly.
+		return "string", dictType
+	case "FLOAT":
+		if c.Precision != nil && *c.Precision == 126 {
+			return "float64", ""
+		}
+		return "float64", dictType
+	case "BINARY_FLOAT":
+		return "float32", "binary_float"
+	case "BINARY_DOUBLE":
+		return "float64", "binary_double"
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+		return "string", dictType
+	case "CLOB", "NCLOB", "LONG":
+		return "string", dictType
+	case "BLOB", "LONG RAW":
+		return "[]byte", dictType
+	case "RAW":
+		return "[]byte", dictType
+	case "DATE":
+		return "time.Time", "date"
+	}
+	if strings.HasPrefix(c.DataType, "TIMESTAMP") {
+		if strings.HasSuffix(c.DataType, "TIME ZONE") {
+			// bun would drop the time zone of a time.Time.
+			return "oratime.Time", dictType
+		}
+		return "time.Time", dictType
+	}
+	if strings.HasPrefix(c.DataType, "INTERVAL DAY") {
+		return "oratime.Duration", dictType
+	}
+	return "string", dictType
+}
+
+func belongsToName(fk *oraschema.ForeignKey, parent *model) string {
+	if len(fk.Columns) == 1 {
+		col := strings.ToUpper(fk.Columns[0])
+		if base, ok := strings.CutSuffix(col, "_ID"); ok && base != "" {
+			return goName(base)
+		}
+	}
+	return parent.Name
+}
+
+func joinTag(cols, refCols []string) string {
+	parts := make([]string, len(cols))
+	for i := range cols {
+		parts[i] = "join:" + cols[i] + "=" + refCols[i]
+	}
+	return strings.Join(parts, ",")
+}
+
+func writeImports(buf *bytes.Buffer, models map[string]*model) {
+	var std, ext []string
+	if usesType(models, "time.Time") {
+		std = append(std, "time")
+	}
+	if usesType(models, "oranum.Decimal") {
+		ext = append(ext, "github.com/lake-of-dreams/bundb-oracle/oranum")
+	}
+	if usesType(models, "oratime.Time") || usesType(models, "oratime.Duration") {
+		ext = append(ext, "github.com/lake-of-dreams/bundb-oracle/oratime")
+	}
+	ext = append(ext, "github.com/uptrace/bun")
+
+	if len(std) == 0 && len(ext) == 1 {
+		fmt.Fprintf(buf, "import %q\n", ext[0])
+		return
+	}
+	buf.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(buf, "\t%q\n", path)
+	}
+	if len(std) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, path := range ext {
+		fmt.Fprintf(buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n")
+}
+
+func usesType(models map[string]*model, typ string) bool {
+	for _, m := range models {
+		for _, f := range m.Fields {
+			if f.Type == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// unique returns name, or name with the smallest numeric suffix not in used,
+// and marks the result as used.
+func unique(used map[string]bool, name string) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -0,0 +1,129 @@
+package oragen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oragen"
+	"github.com/lake-of-dreams/bundb-oracle/oraschema"
+	"github.com/lake-of-dreams/bundb-oracle/oratest"
+)
+
+func ptr(n int64) *int64 {
+	return &n
+}
+
+func number(name string, precision, scale *int64, nullable bool) *oraschema.Column {
+	return &oraschema.Column{Name: name, DataType: "NUMBER", DataLength: 22,
+		Precision: precision, Scale: scale, Nullable: nullable}
+}
+
+func varchar(name string, n int64, nullable bool) *oraschema.Column {
+	return &oraschema.Column{Name: name, DataType: "VARCHAR2", DataLength: n, CharLength: n, Nullable: nullable}
+}
+
+func typed(name, dataType string, nullable bool) *oraschema.Column {
+	return &oraschema.Column{Name: name, DataType: dataType, Nullable: nullable}
+}
+
+// shop is a small schema as ReadTables returns it, covering the column
+// types, keys and relations Generate handles.
+func shop() map[string]*oraschema.Table {
+	id := &oraschema.Column{Name: "ID", DataType: "NUMBER", DataLength: 22, Scale: ptr(0), Identity: true}
+	customers := &oraschema.Table{
+		Owner: "SHOP", Name: "CUSTOMERS",
+		Columns: []*oraschema.Column{
+			id,
+			varchar("NAME", 100, false),
+			varchar("EMAIL", 320, true),
+			number("BALANCE", ptr(12), ptr(2), true),
+			number("POINTS", ptr(9), ptr(0), true),
+			typed("CREATED_AT", "TIMESTAMP(6) WITH TIME ZONE", false),
+		},
+		PrimaryKey: []string{"ID"},
+		Indexes:    []*oraschema.Index{{Name: "CUSTOMERS_EMAIL_UQ", Unique: true, Columns: []string{"EMAIL"}}},
+	}
+	profiles := &oraschema.Table{
+		Owner: "SHOP", Name: "PROFILES",
+		Columns: []*oraschema.Column{
+			number("CUSTOMER_ID", nil, ptr(0), false),
+			typed("BIO", "CLOB", true),
+			&oraschema.Column{Name: "AVATAR", DataType: "RAW", DataLength: 2000, Nullable: true},
+		},
+		PrimaryKey: []string{"CUSTOMER_ID"},
+		Indexes:    []*oraschema.Index{{Name: "SYS_C001", Unique: true, Columns: []string{"CUSTOMER_ID"}}},
+		ForeignKeys: []*oraschema.ForeignKey{{Name: "PROFILES_CUSTOMER_FK",
+			Columns: []string{"CUSTOMER_ID"}, RefOwner: "SHOP", RefTable: "CUSTOMERS", RefColumns: []string{"ID"}}},
+	}
+	orders := &oraschema.Table{
+		Owner: "SHOP", Name: "ORDERS",
+		Columns: []*oraschema.Column{
+			id,
+			number("CUSTOMER_ID", nil, ptr(0), false),
+			// Named like the belongs-to relation on CUSTOMER_ID.
+			varchar("CUSTOMER", 100, true),
+			typed("PLACED", "DATE", false),
+			number("TOTAL", nil, nil, true),
+			&oraschema.Column{Name: "RATE", DataType: "FLOAT", Precision: ptr(126), Nullable: true},
+			typed("SHIPPED_AT", "TIMESTAMP(6)", true),
+		},
+		PrimaryKey: []string{"ID"},
+		ForeignKeys: []*oraschema.ForeignKey{{Name: "ORDERS_CUSTOMER_FK",
+			Columns: []string{"CUSTOMER_ID"}, RefOwner: "SHOP", RefTable: "CUSTOMERS", RefColumns: []string{"ID"}}},
+	}
+	orderItems := &oraschema.Table{
+		Owner: "SHOP", Name: "ORDER_ITEMS",
+		Columns: []*oraschema.Column{
+			number("ORDER_ID", nil, ptr(0), false),
+			number("LINE_NO", ptr(5), ptr(0), false),
+			number("QUANTITY", ptr(38), ptr(0), false),
+			typed("LEAD_TIME", "INTERVAL DAY(2) TO SECOND(6)", true),
+			typed("WEIGHT", "BINARY_DOUBLE", true),
+		},
+		PrimaryKey: []string{"ORDER_ID", "LINE_NO"},
+		ForeignKeys: []*oraschema.ForeignKey{
+			{Name: "ORDER_ITEMS_ORDER_FK", Columns: []string{"ORDER_ID"},
+				RefOwner: "SHOP", RefTable: "ORDERS", RefColumns: []string{"ID"}},
+			// A table of another schema is not generated, so neither is
+			// the relation.
+			{Name: "ORDER_ITEMS_SKU_FK", Columns: []string{"SKU"},
+				RefOwner: "CATALOG", RefTable: "CATEGORIES", RefColumns: []string{"SKU"}},
+		},
+	}
+	categories := &oraschema.Table{
+		Owner: "SHOP", Name: "categories",
+		Columns: []*oraschema.Column{
+			number("id", ptr(10), ptr(0), false),
+			&oraschema.Column{Name: "code", DataType: "CHAR", DataLength: 3, CharLength: 3},
+		},
+		PrimaryKey: []string{"id"},
+	}
+	return map[string]*oraschema.Table{
+		customers.Name:  customers,
+		profiles.Name:   profiles,
+		orders.Name:     orders,
+		orderItems.Name: orderItems,
+		categories.Name: categories,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var sb strings.Builder
+	if err := oragen.Generate(&sb, "shop", shop()); err != nil {
+		t.Fatal(err)
+	}
+	oratest.Golden(t, "shop.go", sb.String())
+}
+
+func TestGenerateImports(t *testing.T) {
+	tables := map[string]*oraschema.Table{
+		"TAGS": {Owner: "SHOP", Name: "TAGS", Columns: []*oraschema.Column{varchar("NAME", 30, false)}},
+	}
+	var sb strings.Builder
+	if err := oragen.Generate(&sb, "shop", tables); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "\nimport \"github.com/uptrace/bun\"\n") {
+		t.Errorf("got\n%s\nwant a single bun import", sb.String())
+	}
+}
@@ -0,0 +1,65 @@
+// Generated by genmodels from the Oracle data dictionary.
+
+package shop
+
+import (
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+)
+
+type Customer struct {
+	bun.BaseModel `bun:"table:CUSTOMERS,alias:c"`
+
+	ID        int64          `bun:"ID,pk,autoincrement"`
+	Name      string         `bun:"NAME,notnull,type:varchar2(100)"`
+	Email     string         `bun:"EMAIL,nullzero,type:varchar2(320)"`
+	Balance   oranum.Decimal `bun:"BALANCE,nullzero,type:number(12,2)"`
+	Points    int64          `bun:"POINTS,nullzero,type:number(9)"`
+	CreatedAt oratime.Time   `bun:"CREATED_AT,notnull,type:timestamp(6) with time zone"`
+	Orders    []*Order       `bun:"rel:has-many,join:ID=CUSTOMER_ID"`
+	Profile   *Profile       `bun:"rel:has-one,join:ID=CUSTOMER_ID"`
+}
+
+type Order struct {
+	bun.BaseModel `bun:"table:ORDERS,alias:o"`
+
+	ID         int64          `bun:"ID,pk,autoincrement"`
+	CustomerID int64          `bun:"CUSTOMER_ID,notnull"`
+	Customer   string         `bun:"CUSTOMER,nullzero,type:varchar2(100)"`
+	Placed     time.Time      `bun:"PLACED,notnull,type:date"`
+	Total      oranum.Decimal `bun:"TOTAL,nullzero,type:number"`
+	Rate       float64        `bun:"RATE,nullzero"`
+	ShippedAt  time.Time      `bun:"SHIPPED_AT,nullzero,type:timestamp(6)"`
+	Customer2  *Customer      `bun:"rel:belongs-to,join:CUSTOMER_ID=ID"`
+	OrderItems []*OrderItem   `bun:"rel:has-many,join:ID=ORDER_ID"`
+}
+
+type OrderItem struct {
+	bun.BaseModel `bun:"table:ORDER_ITEMS,alias:oi"`
+
+	OrderID  int64            `bun:"ORDER_ID,pk"`
+	LineNo   int64            `bun:"LINE_NO,pk,type:number(5)"`
+	Quantity string           `bun:"QUANTITY,notnull,type:number(38)"`
+	LeadTime oratime.Duration `bun:"LEAD_TIME,nullzero,type:interval day(2) to second(6)"`
+	Weight   float64          `bun:"WEIGHT,nullzero,type:binary_double"`
+	Order    *Order           `bun:"rel:belongs-to,join:ORDER_ID=ID"`
+}
+
+type Profile struct {
+	bun.BaseModel `bun:"table:PROFILES,alias:p"`
+
+	CustomerID int64     `bun:"CUSTOMER_ID,pk"`
+	Bio        string    `bun:"BIO,nullzero,type:clob"`
+	Avatar     []byte    `bun:"AVATAR,nullzero,type:raw(2000)"`
+	Customer   *Customer `bun:"rel:belongs-to,join:CUSTOMER_ID=ID"`
+}
+
+type Category struct {
+	bun.BaseModel `bun:"table:categories,alias:c2"`
+
+	ID   int64  `bun:"id,pk,type:number(10)"`
+	Code string `bun:"code,notnull,type:char(3)"`
+}
@@ -0,0 +1,321 @@
+// Package oraload bulk loads bun models into Oracle with array binding: one
+// prepared INSERT executed once per batch, with a slice of values for each
+// column, which go-ora sends as a single round trip. This is much faster than
+// bun's multi-row INSERT for large loads and has no statement size limit.
+package oraload
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+type config struct {
+	batchSize int
+	columns   []string
+	progress  func(Stats)
+}
+
+// Option configures Load.
+type Option func(c *config)
+
+// WithBatchSize sets how many rows are sent per execution. The default is
+// 1000.
+func WithBatchSize(n int) Option {
+	return func(c *config) {
+		c.batchSize = max(n, 1)
+	}
+}
+
+// WithColumns sets the columns to insert. The default is every column of
+// the model except autoincrement ones, which the database generates, and
+// the soft_delete one, which loaded rows leave NULL.
+func WithColumns(columns ...string) Option {
+	return func(c *config) {
+		c.columns = columns
+	}
+}
+
+// WithProgress calls fn with the running totals after each batch.
+func WithProgress(fn func(Stats)) Option {
+	return func(c *config) {
+		c.progress = fn
+	}
+}
+
+// Stats reports the progress of a load.
+type Stats struct {
+	// Rows and Batches count the rows and batches loaded successfully.
+	Rows    int
+	Batches int
+	Elapsed time.Duration
+}
+
+// RowsPerSecond returns the throughput of the load.
+func (s Stats) RowsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Rows) / s.Elapsed.Seconds()
+}
+
+// BatchError reports the batch that failed and, when it could be found, the
+// row that failed it.
+type BatchError struct {
+	// Batch is the index of the failed batch, from 0.
+	Batch int
+	// Row is the index in the loaded slice of the row that failed, or -1
+	// when replaying the batch row by row did not fail again.
+	Row int
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	if e.Row < 0 {
+		return fmt.Sprintf("oraload: batch %d: %v", e.Batch, e.Err)
+	}
+	return fmt.Sprintf("oraload: batch %d: row %d: %v", e.Batch, e.Row, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Load inserts rows, a slice or a pointer to a slice of structs or struct
+// pointers, in batches.
+//
+// Each batch is atomic: on a bun.DB or bun.Conn it is committed in its own
+// transaction, and in a bun.Tx it runs under a savepoint of the caller's
+// transaction. When a batch fails it is undone, then replayed row by row and
+// undone again to find the failing row, which is reported in a *BatchError.
+// The batches before it stay loaded and are counted in the returned Stats.
+//
+// Values are bound as database/sql arguments; nullzero fields holding their
+// zero value are bound as NULL. The BeforeAppendModel hooks of the rows run
+// first, as for a bun insert.
+func Load(ctx context.Context, db bun.IDB, rows any, opts ...Option) (Stats, error) {
+	c := &config{batchSize: 1000}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	slice := reflect.Indirect(reflect.ValueOf(rows))
+	if slice.Kind() != reflect.Slice {
+		return Stats{}, fmt.Errorf("oraload: rows must be a slice, got %T", rows)
+	}
+	elemType := slice.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return Stats{}, fmt.Errorf("oraload: rows must be a slice of structs, got %T", rows)
+	}
+	table := db.Dialect().Tables().Get(elemType)
+
+	// bun runs BeforeAppendModel hooks, e.g. oraaudit's, for the inserts
+	// it executes; these rows bypass it.
+	ptr := reflect.New(slice.Type())
+	ptr.Elem().Set(slice)
+	if err := oraaudit.BeforeAppend(ctx, db.NewInsert().Model(ptr.Interface())); err != nil {
+		return Stats{}, err
+	}
+
+	fields, err := c.fields(table)
+	if err != nil {
+		return Stats{}, err
+	}
+	h, err := handleOf(db)
+	if err != nil {
+		return Stats{}, err
+	}
+	query := insertQuery(table, fields)
+
+	var stats Stats
+	start := time.Now()
+	for batch, lo := 0, 0; lo < slice.Len(); batch, lo = batch+1, lo+c.batchSize {
+		hi := min(lo+c.batchSize, slice.Len())
+		args, err := columnArgs(table, fields, slice, lo, hi)
+		if err != nil {
+			return stats, err
+		}
+		if err := h.run(ctx, func(e execer) error {
+			_, err := e.ExecContext(ctx, query, args...)
+			return err
+		}, true); err != nil {
+			row := h.locate(ctx, query, args, lo, hi-lo)
+			return stats, &BatchError{Batch: batch, Row: row, Err: err}
+		}
+		stats.Rows += hi - lo
+		stats.Batches++
+		stats.Elapsed = time.Since(start)
+		if c.progress != nil {
+			c.progress(stats)
+		}
+	}
+	return stats, nil
+}
+
+func (c *config) fields(table *schema.Table) ([]*schema.Field, error) {
+	if c.columns == nil {
+		var fields []*schema.Field
+		for _, f := range table.Fields {
+			if !f.AutoIncrement && f != table.SoftDeleteField {
+				fields = append(fields, f)
+			}
+		}
+		return fields, nil
+	}
+	fields := make([]*schema.Field, len(c.columns))
+	for i, col := range c.columns {
+		f, ok := table.FieldMap[col]
+		if !ok {
+			return nil, fmt.Errorf("oraload: %s has no column %q", table.TypeName, col)
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+func insertQuery(table *schema.Table, fields []*schema.Field) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(string(table.SQLName))
+	b.WriteString(" (")
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(string(f.SQLName))
+	}
+	b.WriteString(") VALUES (")
+	for i := range fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(":" + strconv.Itoa(i+1))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// columnArgs returns one slice of values per field for rows lo to hi.
+func columnArgs(table *schema.Table, fields []*schema.Field, slice reflect.Value, lo, hi int) ([]any, error) {
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		values := make([]any, hi-lo)
+		for j := lo; j < hi; j++ {
+			row := reflect.Indirect(slice.Index(j))
+			if f.NullZero && f.HasZeroValue(row) {
+				continue
+			}
+			v, err := bindValue(f.Value(row).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("oraload: row %d: %s.%s: %w", j, table.TypeName, f.GoName, err)
+			}
+			values[j-lo] = v
+		}
+		args[i] = values
+	}
+	return args, nil
+}
+
+// bindValue converts v as database/sql would, except that it keeps what a
+// driver.Valuer returns: go-ora binds types of its own, such as the
+// TimeStampTZ of an oratime.Time, that database/sql does not know.
+func bindValue(v any) (driver.Value, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// handle runs batches on the database/sql handle under a bun.IDB. bun
+// formats arguments into the query text, so array binds must bypass it.
+type handle struct {
+	db beginner
+	tx *sql.Tx
+}
+
+func handleOf(db bun.IDB) (*handle, error) {
+	switch db := db.(type) {
+	case *bun.DB:
+		return &handle{db: db.DB}, nil
+	case bun.Conn:
+		return &handle{db: db.Conn}, nil
+	case *bun.Conn:
+		return &handle{db: db.Conn}, nil
+	case bun.Tx:
+		return &handle{tx: db.Tx}, nil
+	case *bun.Tx:
+		return &handle{tx: db.Tx}, nil
+	}
+	return nil, fmt.Errorf("oraload: unsupported bun.IDB %T", db)
+}
+
+// run calls fn in a transaction of its own or under a savepoint of the
+// caller's transaction, and keeps its work only when keep is set and fn
+// succeeds.
+func (h *handle) run(ctx context.Context, fn func(e execer) error, keep bool) error {
+	if h.tx != nil {
+		if _, err := h.tx.ExecContext(ctx, "SAVEPOINT oraload"); err != nil {
+			return err
+		}
+		err := fn(h.tx)
+		if err != nil || !keep {
+			if _, rbErr := h.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT oraload"); err == nil {
+				err = rbErr
+			}
+		}
+		return err
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil || !keep {
+		if rbErr := tx.Rollback(); err == nil {
+			err = rbErr
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// locate replays a failed batch of n rows one row at a time, undoing it
+// afterwards, and returns the slice index of the first row that fails, or
+// -1.
+func (h *handle) locate(ctx context.Context, query string, args []any, lo, n int) int {
+	row := -1
+	_ = h.run(ctx, func(e execer) error {
+		rowArgs := make([]any, len(args))
+		for i := 0; i < n; i++ {
+			for j, col := range args {
+				rowArgs[j] = col.([]any)[i]
+			}
+			if _, err := e.ExecContext(ctx, query, rowArgs...); err != nil {
+				row = lo + i
+				return err
+			}
+		}
+		return nil
+	}, false)
+	return row
+}
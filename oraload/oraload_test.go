@@ -0,0 +1,138 @@
+package oraload_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oraload"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+)
+
+const insert = `INSERT INTO "products" ("name", "price", "version", "created_at", "updated_at", "created_by", "updated_by") ` +
+	`VALUES (:1, :2, :3, :4, :5, :6, :7)`
+
+func products(n int) []models.Product {
+	products := make([]models.Product, n)
+	for i := range products {
+		products[i] = models.Product{Name: string(rune('a' + i)), Price: oranum.New(int64(i), 0)}
+	}
+	return products
+}
+
+func TestLoadBatches(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	defer db.Close()
+
+	stamp := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	ctx := oraaudit.WithTime(oraaudit.WithUser(context.Background(), "loader"), func() time.Time { return stamp })
+
+	var progress []int
+	stats, err := oraload.Load(ctx, db, products(5),
+		oraload.WithBatchSize(2),
+		oraload.WithProgress(func(s oraload.Stats) { progress = append(progress, s.Rows) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Rows != 5 || stats.Batches != 3 {
+		t.Errorf("got %+v, want 5 rows in 3 batches", stats)
+	}
+	if !slices.Equal(progress, []int{2, 4, 5}) {
+		t.Errorf("got progress %v, want [2 4 5]", progress)
+	}
+
+	var inserts []orafake.Call
+	for _, c := range rec.Calls() {
+		if c.Query == insert {
+			inserts = append(inserts, c)
+		}
+	}
+	if len(inserts) != 3 {
+		t.Fatalf("got %d inserts, want 3", len(inserts))
+	}
+	tz, _ := oratime.Time{Time: stamp}.Value()
+	want := []any{
+		[]any{"e"}, []any{"4"}, []any{int64(0)},
+		[]any{tz}, []any{tz}, []any{"loader"}, []any{"loader"},
+	}
+	if !reflect.DeepEqual(inserts[2].Args, want) {
+		t.Errorf("got last batch %#v, want %#v", inserts[2].Args, want)
+	}
+	if got := rec.Queries(); got[0] != orafake.Begin || got[2] != orafake.Commit {
+		t.Errorf("got %q, want each batch in a transaction", got)
+	}
+}
+
+func TestLoadReportsFailingRow(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	defer db.Close()
+
+	dup := orafake.OraError(1, "unique constraint violated")
+	rec.On(`^INSERT`).Times(1)           // batch 0
+	rec.On(`^INSERT`).Times(1).Fail(dup) // batch 1
+	rec.On(`^INSERT`).Times(1)           // replay of row 2
+	rec.On(`^INSERT`).Fail(dup)          // replay of row 3
+
+	stats, err := oraload.Load(context.Background(), db, products(6), oraload.WithBatchSize(2))
+	var batchErr *oraload.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Batch != 1 || batchErr.Row != 3 {
+		t.Fatalf("got %v, want batch 1 failing at row 3", err)
+	}
+	if !oraerr.IsUniqueViolation(err) {
+		t.Errorf("got %v, want the unique violation wrapped", err)
+	}
+	if stats.Rows != 2 {
+		t.Errorf("got %d rows loaded, want 2", stats.Rows)
+	}
+
+	want := []string{
+		orafake.Begin, insert, orafake.Commit,
+		orafake.Begin, insert, orafake.Rollback,
+		orafake.Begin, insert, insert, orafake.Rollback,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoadInTxUsesSavepoints(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	defer db.Close()
+	ctx := context.Background()
+
+	rec.On(`^INSERT`).Fail(orafake.OraError(1400, "cannot insert NULL"))
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := oraload.Load(ctx, tx, products(1))
+		return err
+	})
+	var batchErr *oraload.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Row != 0 {
+		t.Fatalf("got %v, want row 0 to fail", err)
+	}
+
+	want := []string{
+		orafake.Begin,
+		"SAVEPOINT oraload", insert, "ROLLBACK TO SAVEPOINT oraload",
+		"SAVEPOINT oraload", insert, "ROLLBACK TO SAVEPOINT oraload",
+		orafake.Rollback,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
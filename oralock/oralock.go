@@ -0,0 +1,169 @@
+// Package oralock implements optimistic locking for bun models with a
+// version column:
+//
+//	Version int64 `bun:",notnull,default:0" oralock:"version"`
+//
+// Update increments the version and only writes rows whose version is still
+// the one the model was read with, so that of two concurrent updates of the
+// same row the second fails with ErrStaleObject instead of silently
+// overwriting the first.
+package oralock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/lake-of-dreams/bundb-oracle/internal/oratx"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// ErrStaleObject is matched by a *StaleObjectError.
+var ErrStaleObject = errors.New("oralock: stale object")
+
+// StaleObjectError reports a row that was changed or deleted since it was
+// read.
+type StaleObjectError struct {
+	Table string
+	// Key is the primary key value, or a []any of them for composite keys.
+	Key any
+	// Version is the version the model was read with.
+	Version int64
+	// Index is the index of the row in a slice update, or 0.
+	Index int
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("oralock: %s %v was changed or deleted since version %d", e.Table, e.Key, e.Version)
+}
+
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+// VersionField returns the field of table tagged oralock:"version", or nil.
+// The tag is separate from bun's, which warns about options it does not
+// know.
+func VersionField(table *schema.Table) *schema.Field {
+	for _, f := range table.Fields {
+		if f.StructField.Tag.Get("oralock") == "version" {
+			return f
+		}
+	}
+	return nil
+}
+
+// Update writes the given columns, or all of them, of model, a pointer to a
+// struct or to a slice of structs or struct pointers, by primary key. The
+// version of each row is incremented in the model and in the table, and the
+// update only matches rows still at the version of the model.
+//
+// A slice is updated row by row in one transaction, or in the caller's when
+// db is a bun.Tx, since bun's bulk UPDATE ... FROM does not exist on Oracle.
+// If any row is stale every version in the model is restored. The rows
+// before it are rolled back in a transaction Update began; in the caller's
+// they stay updated until the caller rolls back.
+func Update(ctx context.Context, db bun.IDB, model any, columns ...string) error {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("oralock: model must be a non-nil pointer, got %T", model)
+	}
+	v = v.Elem()
+
+	var rows []reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		rows = append(rows, v)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			rows = append(rows, reflect.Indirect(v.Index(i)))
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+	default:
+		return fmt.Errorf("oralock: model must point to a struct or a slice, got %T", model)
+	}
+	table := db.Dialect().Tables().Get(rows[0].Type())
+
+	version := VersionField(table)
+	if version == nil {
+		return fmt.Errorf("oralock: %s has no version column", table.TypeName)
+	}
+	switch version.IndirectType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		return fmt.Errorf("oralock: version column %s.%s must be an integer", table.TypeName, version.GoName)
+	}
+	if len(columns) > 0 {
+		for _, col := range columns {
+			if col == version.Name {
+				return fmt.Errorf("oralock: version column %q is set by Update", col)
+			}
+		}
+		columns = append(columns[:len(columns):len(columns)], version.Name)
+	}
+
+	if v.Kind() == reflect.Struct {
+		return update(ctx, db, table, version, rows[0], columns, 0)
+	}
+
+	olds := make([]int64, len(rows))
+	for i, row := range rows {
+		olds[i] = version.Value(row).Int()
+	}
+	err := oratx.Run(ctx, db, func(ctx context.Context, db bun.IDB) error {
+		for i, row := range rows {
+			if err := update(ctx, db, table, version, row, columns, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for i, row := range rows {
+			version.Value(row).SetInt(olds[i])
+		}
+	}
+	return err
+}
+
+// update writes one row, restoring its version in the model on failure.
+func update(
+	ctx context.Context, db bun.IDB, table *schema.Table, version *schema.Field,
+	row reflect.Value, columns []string, index int,
+) error {
+	fv := version.Value(row)
+	old := fv.Int()
+	fv.SetInt(old + 1)
+
+	q := db.NewUpdate().Model(row.Addr().Interface()).WherePK().
+		Where("? = ?", version.SQLName, old)
+	if len(columns) > 0 {
+		q = q.Column(columns...)
+	}
+	res, err := q.Exec(ctx)
+	if err == nil {
+		var n int64
+		if n, err = res.RowsAffected(); err == nil && n == 0 {
+			err = &StaleObjectError{Table: table.Name, Key: key(table, row), Version: old, Index: index}
+		}
+	}
+	if err != nil {
+		fv.SetInt(old)
+	}
+	return err
+}
+
+func key(table *schema.Table, row reflect.Value) any {
+	if len(table.PKs) == 1 {
+		return table.PKs[0].Value(row).Interface()
+	}
+	key := make([]any, len(table.PKs))
+	for i, pk := range table.PKs {
+		key[i] = pk.Value(row).Interface()
+	}
+	return key
+}
@@ -0,0 +1,117 @@
+package oralock_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oralock"
+	"github.com/uptrace/bun"
+)
+
+func offlineDB(t *testing.T) (*bun.DB, *orafake.Recorder) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func TestUpdate(t *testing.T) {
+	db, rec := offlineDB(t)
+	ctx := context.Background()
+	rec.On(`^UPDATE`).Times(1).Affect(1)
+
+	p := &models.Product{ID: 1, Name: "pear", Version: 3}
+	if err := oralock.Update(ctx, db, p, "name"); err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != 4 {
+		t.Errorf("got version %d, want 4", p.Version)
+	}
+
+	// Another writer got there first: nothing matches version 4.
+	err := oralock.Update(ctx, db, p, "name")
+	var stale *oralock.StaleObjectError
+	if !errors.As(err, &stale) || !errors.Is(err, oralock.ErrStaleObject) {
+		t.Fatalf("got %v, want a StaleObjectError", err)
+	}
+	if stale.Key != int64(1) || stale.Version != 4 || p.Version != 4 {
+		t.Errorf("got %+v and version %d, want key 1 and version 4 kept", stale, p.Version)
+	}
+
+	want := []string{
+		`UPDATE "products" SET "name" = 'pear', "version" = 4 WHERE ("version" = 3) AND "products"."deleted_at" IS NULL AND ("id" = 1)`,
+		`UPDATE "products" SET "name" = 'pear', "version" = 5 WHERE ("version" = 4) AND "products"."deleted_at" IS NULL AND ("id" = 1)`,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUpdateSlice(t *testing.T) {
+	db, rec := offlineDB(t)
+	rec.On(`^UPDATE`).Times(1).Affect(1)
+
+	products := []models.Product{{ID: 1, Version: 1}, {ID: 2, Version: 7}}
+	err := oralock.Update(context.Background(), db, &products, "price")
+	var stale *oralock.StaleObjectError
+	if !errors.As(err, &stale) || stale.Index != 1 || stale.Key != int64(2) {
+		t.Fatalf("got %v, want row 1 stale", err)
+	}
+	if products[0].Version != 1 || products[1].Version != 7 {
+		t.Errorf("got versions %d and %d, want 1 and 7 restored", products[0].Version, products[1].Version)
+	}
+
+	want := []string{
+		orafake.Begin,
+		`UPDATE "products" SET "price" = 0, "version" = 2 WHERE ("version" = 1) AND "products"."deleted_at" IS NULL AND ("id" = 1)`,
+		`UPDATE "products" SET "price" = 0, "version" = 8 WHERE ("version" = 7) AND "products"."deleted_at" IS NULL AND ("id" = 2)`,
+		orafake.Rollback,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUpdateRejectsVersionColumn(t *testing.T) {
+	db, rec := offlineDB(t)
+
+	if err := oralock.Update(context.Background(), db, &models.Product{ID: 1}, "version"); err == nil {
+		t.Error("got no error setting the version column")
+	}
+	if n := len(rec.Calls()); n != 0 {
+		t.Errorf("got %d statements, want none", n)
+	}
+}
+
+func TestUpdateSliceInCallersTx(t *testing.T) {
+	db, rec := offlineDB(t)
+	ctx := context.Background()
+	rec.On(`^UPDATE`).Times(1).Affect(1)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	products := []models.Product{{ID: 1, Version: 1}, {ID: 2, Version: 7}}
+	if err := oralock.Update(ctx, tx, &products, "price"); !errors.Is(err, oralock.ErrStaleObject) {
+		t.Fatalf("got %v, want ErrStaleObject", err)
+	}
+	if products[0].Version != 1 || products[1].Version != 7 {
+		t.Errorf("got versions %d and %d, want 1 and 7 restored", products[0].Version, products[1].Version)
+	}
+	// The first row stays updated; rolling back is up to the caller.
+	want := []string{
+		orafake.Begin,
+		`UPDATE "products" SET "price" = 0, "version" = 2 WHERE ("version" = 1) AND "products"."deleted_at" IS NULL AND ("id" = 1)`,
+		`UPDATE "products" SET "price" = 0, "version" = 8 WHERE ("version" = 7) AND "products"."deleted_at" IS NULL AND ("id" = 2)`,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+// Package oramerge builds upserts of bun models as Oracle MERGE statements.
+// bun's On("CONFLICT ...") upserts are PostgreSQL and SQLite syntax, which
+// Oracle does not have.
+package oramerge
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/oralock"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// sourceAlias names the rows merged in, selected from dual.
+const sourceAlias = `"src"`
+
+// UpsertQuery inserts the rows of a model, or updates those that match an
+// existing row on the key columns:
+//
+//	MERGE INTO "products" "u" USING (SELECT ... FROM dual UNION ALL ...) "src"
+//	ON ("u"."name" = "src"."name")
+//	WHEN MATCHED THEN UPDATE SET "u"."price" = "src"."price"
+//	WHEN NOT MATCHED THEN INSERT ("name", "price") VALUES ("src"."name", "src"."price")
+type UpsertQuery struct {
+	db      bun.IDB
+	model   any
+	key     []string
+	columns []string
+	set     []string
+	setSet  bool
+}
+
+// NewUpsert returns an upsert of model, a pointer to a struct or to a slice
+// of structs or struct pointers.
+func NewUpsert(db bun.IDB, model any) *UpsertQuery {
+	return &UpsertQuery{db: db, model: model}
+}
+
+// On sets the key columns that decide whether a row exists. The default is
+// the primary key. The key should be unique, or a source row may match
+// several rows. Soft-deleted rows never match, so a row deleted with a
+// soft_delete column is inserted anew rather than updated.
+func (q *UpsertQuery) On(columns ...string) *UpsertQuery {
+	q.key = columns
+	return q
+}
+
+// Column sets the columns written by the insert. The default is every
+// column except autoincrement ones, which the database generates, and the
+// soft_delete one, which only deletes set.
+func (q *UpsertQuery) Column(columns ...string) *UpsertQuery {
+	q.columns = columns
+	return q
+}
+
+// Set sets the columns updated on a matching row. The default is the
+// inserted columns except the key, the primary key and skipupdate columns.
+// With no columns, matching rows are left as they are. Oracle cannot update
+// key columns. A version column of oralock is incremented instead of copied.
+func (q *UpsertQuery) Set(columns ...string) *UpsertQuery {
+	q.set = columns
+	q.setSet = true
+	return q
+}
+
+// Exec runs the upsert. RowsAffected counts both inserted and updated rows.
+// The BeforeAppendModel hooks of the model run first, as for a bun insert,
+// so that oraaudit stamps the rows; their values are merged in as they are,
+// so stamps are always the client time.
+func (q *UpsertQuery) Exec(ctx context.Context) (sql.Result, error) {
+	if err := oraaudit.BeforeAppend(ctx, q.db.NewInsert().Model(q.model)); err != nil {
+		return nil, err
+	}
+	b, err := q.AppendQuery(schema.NewFormatter(q.db.Dialect()), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Passing the statement as an argument keeps '?' in values from being
+	// taken for placeholders.
+	return q.db.NewRaw("?", bun.Safe(b)).Exec(ctx)
+}
+
+// String returns the MERGE statement, or the error building it.
+func (q *UpsertQuery) String() string {
+	b, err := q.AppendQuery(schema.NewFormatter(q.db.Dialect()), nil)
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+func (q *UpsertQuery) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	rows, table, err := q.rows()
+	if err != nil {
+		return nil, err
+	}
+	key := table.PKs
+	if len(q.key) > 0 {
+		if key, err = fields(table, q.key); err != nil {
+			return nil, err
+		}
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("oramerge: %s has no primary key; set the key with On", table.TypeName)
+	}
+	columns := insertable(table)
+	if len(q.columns) > 0 {
+		if columns, err = fields(table, q.columns); err != nil {
+			return nil, err
+		}
+	}
+	var set []*schema.Field
+	switch {
+	case len(q.set) > 0:
+		if set, err = fields(table, q.set); err != nil {
+			return nil, err
+		}
+		for _, f := range set {
+			if slices.Contains(key, f) {
+				return nil, fmt.Errorf("oramerge: cannot update key column %q", f.Name)
+			}
+		}
+	case !q.setSet:
+		for _, f := range columns {
+			if !f.IsPK && !f.SkipUpdate() && !slices.Contains(key, f) {
+				set = append(set, f)
+			}
+		}
+	}
+	// A version column for oralock is incremented rather than overwritten.
+	version := oralock.VersionField(table)
+
+	// The source selects every column the statement refers to.
+	source := slices.Clone(key)
+	for _, f := range append(slices.Clone(columns), set...) {
+		if !slices.Contains(source, f) {
+			source = append(source, f)
+		}
+	}
+
+	b = append(b, "MERGE INTO "...)
+	b = append(b, table.SQLName...)
+	b = append(b, ' ')
+	b = append(b, table.SQLAlias...)
+	b = append(b, " USING ("...)
+	for i, row := range rows {
+		if i > 0 {
+			b = append(b, " UNION ALL "...)
+		}
+		b = append(b, "SELECT "...)
+		for j, f := range source {
+			if j > 0 {
+				b = append(b, ", "...)
+			}
+			b = f.AppendValue(fmter, b, row)
+			b = append(b, ' ')
+			b = append(b, f.SQLName...)
+		}
+		b = append(b, " FROM dual"...)
+	}
+	b = append(b, ") "+sourceAlias+" ON ("...)
+	for i, f := range key {
+		if i > 0 {
+			b = append(b, " AND "...)
+		}
+		b = appendColumn(b, table.SQLAlias, f)
+		b = append(b, " = "...)
+		b = appendColumn(b, sourceAlias, f)
+	}
+	if table.SoftDeleteField != nil {
+		b = append(b, " AND "...)
+		b = appendColumn(b, table.SQLAlias, table.SoftDeleteField)
+		b = append(b, " IS NULL"...)
+	}
+	b = append(b, ')')
+
+	if len(set) > 0 {
+		b = append(b, " WHEN MATCHED THEN UPDATE SET "...)
+		for i, f := range set {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = appendColumn(b, table.SQLAlias, f)
+			b = append(b, " = "...)
+			if f == version {
+				b = appendColumn(b, table.SQLAlias, f)
+				b = append(b, " + 1"...)
+			} else {
+				b = appendColumn(b, sourceAlias, f)
+			}
+		}
+	}
+
+	b = append(b, " WHEN NOT MATCHED THEN INSERT ("...)
+	for i, f := range columns {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, f.SQLName...)
+	}
+	b = append(b, ") VALUES ("...)
+	for i, f := range columns {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendColumn(b, sourceAlias, f)
+	}
+	b = append(b, ')')
+	return b, nil
+}
+
+// rows returns the structs of the model and their table.
+func (q *UpsertQuery) rows() ([]reflect.Value, *schema.Table, error) {
+	v := reflect.ValueOf(q.model)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, nil, fmt.Errorf("oramerge: model must be a non-nil pointer, got %T", q.model)
+	}
+	v = v.Elem()
+
+	var rows []reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		rows = append(rows, v)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			rows = append(rows, reflect.Indirect(v.Index(i)))
+		}
+		if len(rows) == 0 {
+			return nil, nil, fmt.Errorf("oramerge: upsert of an empty %s", v.Type())
+		}
+	default:
+		return nil, nil, fmt.Errorf("oramerge: model must point to a struct or a slice, got %T", q.model)
+	}
+	if rows[0].Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("oramerge: model must hold structs, got %T", q.model)
+	}
+	return rows, q.db.Dialect().Tables().Get(rows[0].Type()), nil
+}
+
+func fields(table *schema.Table, names []string) ([]*schema.Field, error) {
+	fields := make([]*schema.Field, len(names))
+	for i, name := range names {
+		f, ok := table.FieldMap[name]
+		if !ok {
+			return nil, fmt.Errorf("oramerge: %s has no column %q", table.TypeName, name)
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+// insertable returns the fields that are not autoincrement.
+func insertable(table *schema.Table) []*schema.Field {
+	var out []*schema.Field
+	for _, f := range table.Fields {
+		if !f.AutoIncrement && f != table.SoftDeleteField {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func appendColumn(b []byte, alias schema.Safe, f *schema.Field) []byte {
+	b = append(b, alias...)
+	b = append(b, '.')
+	return append(b, f.SQLName...)
+}
@@ -0,0 +1,96 @@
+package oramerge_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oramerge"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/uptrace/bun"
+)
+
+func offlineDB(t *testing.T) (*bun.DB, *orafake.Recorder) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func TestUpsertSQL(t *testing.T) {
+	db, _ := offlineDB(t)
+	products := []models.Product{
+		{Name: "apple", Price: oranum.MustParse("5.99")},
+		{Name: "pear", Price: oranum.New(2, 0)},
+	}
+
+	tests := []struct {
+		name string
+		q    *oramerge.UpsertQuery
+		want string
+	}{{
+		name: "primary key",
+		q:    oramerge.NewUpsert(db, &models.Product{ID: 7, Name: "apple", Price: oranum.MustParse("5.99")}),
+		want: `MERGE INTO "products" "u" USING (SELECT 7 "id", 'apple' "name", 5.99 "price", 0 "version", ` +
+			`NULL "created_at", NULL "updated_at", NULL "created_by", NULL "updated_by" FROM dual) "src" ` +
+			`ON ("u"."id" = "src"."id" AND "u"."deleted_at" IS NULL) ` +
+			`WHEN MATCHED THEN UPDATE SET "u"."name" = "src"."name", "u"."price" = "src"."price", "u"."version" = "u"."version" + 1, ` +
+			`"u"."updated_at" = "src"."updated_at", "u"."updated_by" = "src"."updated_by" ` +
+			`WHEN NOT MATCHED THEN INSERT ("name", "price", "version", "created_at", "updated_at", "created_by", "updated_by") ` +
+			`VALUES ("src"."name", "src"."price", "src"."version", "src"."created_at", "src"."updated_at", "src"."created_by", "src"."updated_by")`,
+	}, {
+		name: "slice on a natural key",
+		q:    oramerge.NewUpsert(db, &products).On("name").Column("name", "price"),
+		want: `MERGE INTO "products" "u" USING (` +
+			`SELECT 'apple' "name", 5.99 "price" FROM dual UNION ALL ` +
+			`SELECT 'pear' "name", 2 "price" FROM dual) "src" ` +
+			`ON ("u"."name" = "src"."name" AND "u"."deleted_at" IS NULL) ` +
+			`WHEN MATCHED THEN UPDATE SET "u"."price" = "src"."price" ` +
+			`WHEN NOT MATCHED THEN INSERT ("name", "price") VALUES ("src"."name", "src"."price")`,
+	}, {
+		name: "insert only",
+		q:    oramerge.NewUpsert(db, &products).On("name").Column("name").Set(),
+		want: `MERGE INTO "products" "u" USING (` +
+			`SELECT 'apple' "name" FROM dual UNION ALL SELECT 'pear' "name" FROM dual) "src" ` +
+			`ON ("u"."name" = "src"."name" AND "u"."deleted_at" IS NULL) ` +
+			`WHEN NOT MATCHED THEN INSERT ("name") VALUES ("src"."name")`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpsertRejectsKeyUpdate(t *testing.T) {
+	db, rec := offlineDB(t)
+
+	_, err := oramerge.NewUpsert(db, &models.Product{Name: "apple"}).On("name").Set("name").Exec(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "key column") {
+		t.Fatalf("got %v, want an error updating the key", err)
+	}
+	if n := len(rec.Calls()); n != 0 {
+		t.Errorf("got %d statements, want none", n)
+	}
+}
+
+func TestUpsertKeepsQuestionMarks(t *testing.T) {
+	db, rec := offlineDB(t)
+	rec.On(`^MERGE`).Affect(1)
+
+	res, err := oramerge.NewUpsert(db, &models.Product{Name: "why?"}).On("name").Exec(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("got %d rows affected, want 1", n)
+	}
+	if got := rec.Queries(); len(got) != 1 || !strings.Contains(got[0], `'why?' "name"`) {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package oramigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/uptrace/bun"
+)
+
+// ErrLocked is returned when another process holds the migration lock.
+var ErrLocked = errors.New("oramigrate: migrations are locked by another session")
+
+// Locker serializes migration runs across processes.
+type Locker interface {
+	// Init creates whatever the locker needs in the database.
+	Init(ctx context.Context, db *bun.DB) error
+	// Lock acquires the lock and returns a function releasing it.
+	Lock(ctx context.Context, db *bun.DB) (unlock func(ctx context.Context) error, err error)
+}
+
+// TableLocker locks a single row of a lock table with SELECT ... FOR UPDATE
+// NOWAIT. The lock lives in a transaction on a dedicated connection, so it
+// is released when the process dies. It needs no privileges beyond those of
+// the schema owner.
+type TableLocker struct {
+	Table string
+}
+
+var _ Locker = (*TableLocker)(nil)
+
+type lockRow struct {
+	bun.BaseModel
+
+	ID int64 `bun:",pk"`
+}
+
+func (l *TableLocker) table() string {
+	if l.Table != "" {
+		return l.Table
+	}
+	return "bun_migration_lock"
+}
+
+func (l *TableLocker) Init(ctx context.Context, db *bun.DB) error {
+	if _, err := db.NewCreateTable().
+		Model((*lockRow)(nil)).
+		ModelTableExpr(l.table()).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+	_, err := db.NewInsert().
+		Model(&lockRow{ID: 1}).
+		ModelTableExpr(l.table()).
+		Exec(ctx)
+	if oraerr.IsUniqueViolation(err) {
+		return nil
+	}
+	return err
+}
+
+func (l *TableLocker) Lock(ctx context.Context, db *bun.DB) (func(ctx context.Context) error, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	err = tx.NewSelect().
+		Model((*lockRow)(nil)).
+		ModelTableExpr(l.table()).
+		Column("id").
+		Where("? = 1", bun.Ident("id")).
+		For("UPDATE NOWAIT").
+		Scan(ctx, &id)
+	if err != nil {
+		_ = tx.Rollback()
+		if oraerr.IsResourceBusy(err) {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+	return func(context.Context) error {
+		return tx.Rollback()
+	}, nil
+}
+
+// DBMSLocker takes an exclusive user lock with DBMS_LOCK. The lock is held
+// by a dedicated session and released when that session ends. The schema
+// owner needs EXECUTE on SYS.DBMS_LOCK.
+type DBMSLocker struct {
+	// Name identifies the lock, "BUN_MIGRATIONS" by default.
+	Name string
+	// Timeout is how long to wait for the lock; zero fails immediately.
+	Timeout time.Duration
+}
+
+var _ Locker = (*DBMSLocker)(nil)
+
+func (l *DBMSLocker) name() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return "BUN_MIGRATIONS"
+}
+
+func (l *DBMSLocker) Init(context.Context, *bun.DB) error {
+	return nil
+}
+
+// ALLOCATE_UNIQUE commits, which is harmless on a connection reserved for
+// the lock. REQUEST returns 0 on success and 4 if the session already owns
+// the lock; 1 means timeout.
+const dbmsLockRequest = `DECLARE
+  h VARCHAR2(128);
+  r INTEGER;
+BEGIN
+  DBMS_LOCK.ALLOCATE_UNIQUE(%s, h);
+  r := DBMS_LOCK.REQUEST(h, DBMS_LOCK.X_MODE, %d, FALSE);
+  IF r = 1 THEN
+    RAISE_APPLICATION_ERROR(-20001, 'lock timeout');
+  ELSIF r NOT IN (0, 4) THEN
+    RAISE_APPLICATION_ERROR(-20002, 'DBMS_LOCK.REQUEST returned ' || r);
+  END IF;
+END;`
+
+const dbmsLockRelease = `DECLARE
+  h VARCHAR2(128);
+  r INTEGER;
+BEGIN
+  DBMS_LOCK.ALLOCATE_UNIQUE(%s, h);
+  r := DBMS_LOCK.RELEASE(h);
+END;`
+
+func (l *DBMSLocker) Lock(ctx context.Context, db *bun.DB) (func(ctx context.Context) error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := "'" + strings.ReplaceAll(l.name(), "'", "''") + "'"
+	_, err = conn.ExecContext(ctx, fmt.Sprintf(dbmsLockRequest, name, int(l.Timeout/time.Second)))
+	if err != nil {
+		_ = conn.Close()
+		if code, _ := oraerr.Code(err); code == 20001 {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(dbmsLockRelease, name))
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
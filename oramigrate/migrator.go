@@ -0,0 +1,286 @@
+// Package oramigrate runs bun migrations against Oracle: it splits SQL files
+// with PL/SQL in mind, serializes runs with an Oracle lock and, because
+// Oracle commits every DDL statement implicitly, records partially applied
+// migrations so they can be reported and repaired by hand.
+package oramigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+const (
+	defaultTable         = "bun_migrations"
+	defaultFailuresTable = "bun_migration_failures"
+)
+
+// Failure records a migration that did not complete.
+type Failure struct {
+	bun.BaseModel
+
+	Name      string `bun:",pk"`
+	Direction string
+	// Statement is the 1-based index of the failed statement of a SQL
+	// migration, or 0 for Go migrations.
+	Statement int
+	Error     string    `bun:"type:varchar2(4000)"`
+	FailedAt  time.Time `bun:",notnull"`
+}
+
+// Status is a migration together with its last failure, if any.
+type Status struct {
+	migrate.Migration
+	Failure *Failure
+}
+
+// Option configures NewMigrator.
+type Option func(m *Migrator)
+
+// WithTableName overrides the migrations table, bun_migrations by default.
+func WithTableName(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithFailuresTableName overrides the failures table,
+// bun_migration_failures by default.
+func WithFailuresTableName(table string) Option {
+	return func(m *Migrator) {
+		m.failuresTable = table
+	}
+}
+
+// WithLocker replaces the default TableLocker.
+func WithLocker(locker Locker) Option {
+	return func(m *Migrator) {
+		m.locker = locker
+	}
+}
+
+// Migrator applies and rolls back migrations one at a time, marking each
+// applied only after it succeeded.
+type Migrator struct {
+	*migrate.Migrator
+
+	db            *bun.DB
+	migrations    *migrate.Migrations
+	locker        Locker
+	table         string
+	failuresTable string
+}
+
+func NewMigrator(db *bun.DB, migrations *migrate.Migrations, opts ...Option) *Migrator {
+	m := &Migrator{
+		db:            db,
+		migrations:    migrations,
+		locker:        &TableLocker{},
+		table:         defaultTable,
+		failuresTable: defaultFailuresTable,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.Migrator = migrate.NewMigrator(db, migrations,
+		migrate.WithTableName(m.table),
+		migrate.WithMarkAppliedOnSuccess(true),
+	)
+	return m
+}
+
+// Init creates the migrations, failures and lock tables.
+func (m *Migrator) Init(ctx context.Context) error {
+	if err := m.Migrator.Init(ctx); err != nil {
+		return err
+	}
+	if _, err := m.db.NewCreateTable().
+		Model((*Failure)(nil)).
+		ModelTableExpr(m.failuresTable).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+	return m.locker.Init(ctx, m.db)
+}
+
+// Up applies all unapplied migrations as one group. It stops at the first
+// failure, which is recorded and returned; migrations before it stay
+// applied.
+func (m *Migrator) Up(ctx context.Context) (*migrate.MigrationGroup, error) {
+	unlock, err := m.locker.Lock(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(ctx)
+
+	ms, err := m.MigrationsWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &migrate.MigrationGroup{ID: ms.LastGroupID() + 1}
+	for _, migration := range ms.Unapplied() {
+		migration.GroupID = group.ID
+		if migration.Up != nil {
+			if err := migration.Up(ctx, m.db); err != nil {
+				return group, m.fail(ctx, &migration, "up", err)
+			}
+		}
+		if err := m.MarkApplied(ctx, &migration); err != nil {
+			return group, err
+		}
+		if err := m.clearFailure(ctx, &migration); err != nil {
+			return group, err
+		}
+		group.Migrations = append(group.Migrations, migration)
+	}
+	return group, nil
+}
+
+// Down rolls back the last applied group in reverse order.
+func (m *Migrator) Down(ctx context.Context) (*migrate.MigrationGroup, error) {
+	unlock, err := m.locker.Lock(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(ctx)
+
+	ms, err := m.MigrationsWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	last := ms.LastGroup()
+	group := &migrate.MigrationGroup{ID: last.ID}
+	for i := len(last.Migrations) - 1; i >= 0; i-- {
+		migration := last.Migrations[i]
+		if migration.Down != nil {
+			if err := migration.Down(ctx, m.db); err != nil {
+				return group, m.fail(ctx, &migration, "down", err)
+			}
+		}
+		if err := m.markUnapplied(ctx, &migration); err != nil {
+			return group, err
+		}
+		if err := m.clearFailure(ctx, &migration); err != nil {
+			return group, err
+		}
+		group.Migrations = append(group.Migrations, migration)
+	}
+	return group, nil
+}
+
+// Status returns all migrations in ascending order with their failures.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	ms, err := m.MigrationsWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []Failure
+	if err := m.db.NewSelect().
+		ColumnExpr("*").
+		Model(&failures).
+		ModelTableExpr(m.failuresTable).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Failure, len(failures))
+	for i := range failures {
+		byName[failures[i].Name] = &failures[i]
+	}
+
+	statuses := make([]Status, len(ms))
+	for i, migration := range ms {
+		statuses[i] = Status{Migration: migration, Failure: byName[migration.Name]}
+	}
+	return statuses, nil
+}
+
+// markUnapplied replaces bun's MarkUnapplied, whose unquoted "id" does not
+// match the lower-case column bun creates on Oracle.
+func (m *Migrator) markUnapplied(ctx context.Context, migration *migrate.Migration) error {
+	_, err := m.db.NewDelete().
+		Model(migration).
+		ModelTableExpr(m.table).
+		Where("? = ?", bun.Ident("id"), migration.ID).
+		Exec(ctx)
+	return err
+}
+
+func (m *Migrator) fail(ctx context.Context, migration *migrate.Migration, direction string, err error) error {
+	failure := &Failure{
+		Name:      migration.Name,
+		Direction: direction,
+		Error:     err.Error(),
+		FailedAt:  time.Now(),
+	}
+	var stmtErr *StatementError
+	if errors.As(err, &stmtErr) {
+		failure.Statement = stmtErr.Index
+	}
+	if len(failure.Error) > 4000 {
+		failure.Error = failure.Error[:4000]
+	}
+
+	err = fmt.Errorf("oramigrate: %s %s: %w", direction, migration, err)
+	if cerr := m.clearFailure(ctx, migration); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	if _, ierr := m.db.NewInsert().
+		Model(failure).
+		ModelTableExpr(m.failuresTable).
+		Exec(ctx); ierr != nil {
+		return errors.Join(err, ierr)
+	}
+	return err
+}
+
+func (m *Migrator) clearFailure(ctx context.Context, migration *migrate.Migration) error {
+	_, err := m.db.NewDelete().
+		Model((*Failure)(nil)).
+		ModelTableExpr(m.failuresTable).
+		Where("? = ?", bun.Ident("name"), migration.Name).
+		Exec(ctx)
+	return err
+}
+
+var nameRE = regexp.MustCompile(`^[0-9a-z_\-]+$`)
+
+const sqlUpTemplate = `-- Statements end with ';'. End PL/SQL blocks, procedures, packages,
+-- triggers and types with a line holding a single '/'.
+-- Oracle commits every DDL statement, so keep each migration small.
+
+`
+
+const sqlDownTemplate = `-- Undo the statements of the matching .up.sql file, in reverse order.
+
+`
+
+// CreateSQL writes empty up and down SQL migration files to dir and returns
+// their paths.
+func (m *Migrator) CreateSQL(dir, name string) ([]string, error) {
+	if !nameRE.MatchString(name) {
+		return nil, fmt.Errorf("oramigrate: invalid migration name: %q", name)
+	}
+	base := time.Now().UTC().Format("20060102150405") + "_" + name
+
+	paths := []string{
+		filepath.Join(dir, base+".up.sql"),
+		filepath.Join(dir, base+".down.sql"),
+	}
+	for i, content := range []string{sqlUpTemplate, sqlDownTemplate} {
+		if err := os.WriteFile(paths[i], []byte(content), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
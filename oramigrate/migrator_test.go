@@ -0,0 +1,199 @@
+package oramigrate_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oramigrate"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+const lockQuery = `SELECT "lock_row"."id" FROM bun_migration_lock WHERE ("id" = 1) FOR UPDATE NOWAIT`
+
+var appliedAt = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+// newMigrator returns a Migrator over the migrations names, each of which
+// logs its runs to *ran.
+func newMigrator(t *testing.T, ran *[]string, names ...string) (*orafake.Recorder, *oramigrate.Migrator) {
+	t.Helper()
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+
+	migrations := migrate.NewMigrations()
+	for _, name := range names {
+		migrations.Add(migrate.Migration{
+			Name: name,
+			Up: func(context.Context, *bun.DB) error {
+				*ran = append(*ran, "up "+name)
+				return nil
+			},
+			Down: func(context.Context, *bun.DB) error {
+				*ran = append(*ran, "down "+name)
+				return nil
+			},
+		})
+	}
+	rec.On(`FOR UPDATE NOWAIT`).Return([]string{"id"}, []any{1})
+	return rec, oramigrate.NewMigrator(db, migrations)
+}
+
+// applied scripts the migrations table to hold rows of id, name and group.
+func applied(rec *orafake.Recorder, rows ...[]any) {
+	full := make([][]any, len(rows))
+	for i, row := range rows {
+		full[i] = append(row, appliedAt)
+	}
+	rec.On(`^SELECT \* FROM bun_migrations$`).Return([]string{"id", "name", "group_id", "migrated_at"}, full...)
+}
+
+func TestUp(t *testing.T) {
+	var ran []string
+	rec, m := newMigrator(t, &ran, "20261001000000", "20261002000000", "20261003000000")
+	applied(rec, []any{1, "20261001000000", 1})
+
+	group, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != 2 || len(group.Migrations) != 2 {
+		t.Errorf("got group %d with %d migrations, want group 2 with 2", group.ID, len(group.Migrations))
+	}
+	if want := []string{"up 20261002000000", "up 20261003000000"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+	// Each migration is marked applied right after it ran, inside the lock.
+	want := []string{
+		orafake.Begin,
+		lockQuery,
+		`SELECT * FROM bun_migrations`,
+		`INSERT INTO bun_migrations ("name", "group_id") VALUES ('20261002000000', 2)`,
+		`DELETE FROM bun_migration_failures WHERE ("name" = '20261002000000')`,
+		`INSERT INTO bun_migrations ("name", "group_id") VALUES ('20261003000000', 2)`,
+		`DELETE FROM bun_migration_failures WHERE ("name" = '20261003000000')`,
+		orafake.Rollback,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUpFailure(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	rec.On(`FOR UPDATE NOWAIT`).Return([]string{"id"}, []any{1})
+
+	fsys := fstest.MapFS{
+		"20261001000000_orders.up.sql": {Data: []byte("CREATE TABLE orders (id NUMBER);\nCREATE INDEX orders_ix ON orders (nope);\n")},
+	}
+	migrations := migrate.NewMigrations()
+	if err := oramigrate.Discover(migrations, fsys); err != nil {
+		t.Fatal(err)
+	}
+	rec.On(`^CREATE INDEX`).Fail(orafake.OraError(904, `"NOPE": invalid identifier`))
+
+	_, err := oramigrate.NewMigrator(db, migrations).Up(context.Background())
+	var stmtErr *oramigrate.StatementError
+	if !errors.As(err, &stmtErr) || stmtErr.Index != 2 {
+		t.Fatalf("got %v, want statement 2 to fail", err)
+	}
+
+	// The migration is not marked applied and the failure is recorded.
+	var inserts []orafake.Call
+	for _, c := range rec.Calls() {
+		if strings.HasPrefix(c.Query, "INSERT") {
+			inserts = append(inserts, c)
+		}
+	}
+	if len(inserts) != 1 || !strings.HasPrefix(inserts[0].Query,
+		`INSERT INTO bun_migration_failures ("name", "direction", "statement", "error", "failed_at") VALUES ('20261001000000', 'up', 2, `) {
+		t.Errorf("got inserts %q, want only the failure", inserts)
+	}
+}
+
+func TestDown(t *testing.T) {
+	var ran []string
+	rec, m := newMigrator(t, &ran, "20261001000000", "20261002000000", "20261003000000")
+	applied(rec,
+		[]any{1, "20261001000000", 1},
+		[]any{2, "20261002000000", 2},
+		[]any{3, "20261003000000", 2},
+	)
+
+	group, err := m.Down(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != 2 || len(group.Migrations) != 2 {
+		t.Errorf("got group %d with %d migrations, want group 2 with 2", group.ID, len(group.Migrations))
+	}
+	if want := []string{"down 20261003000000", "down 20261002000000"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+	want := []string{
+		orafake.Begin,
+		lockQuery,
+		`SELECT * FROM bun_migrations`,
+		`DELETE FROM bun_migrations WHERE ("id" = 3)`,
+		`DELETE FROM bun_migration_failures WHERE ("name" = '20261003000000')`,
+		`DELETE FROM bun_migrations WHERE ("id" = 2)`,
+		`DELETE FROM bun_migration_failures WHERE ("name" = '20261002000000')`,
+		orafake.Rollback,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	var ran []string
+	rec, m := newMigrator(t, &ran, "20261001000000", "20261002000000")
+	applied(rec, []any{1, "20261001000000", 1})
+	rec.On(`^SELECT \* FROM bun_migration_failures$`).Return(
+		[]string{"name", "direction", "statement", "error", "failed_at"},
+		[]any{"20261002000000", "up", 3, "ORA-00955: name is already used by an existing object", appliedAt},
+	)
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses, want 2", len(statuses))
+	}
+	if s := statuses[0]; !s.IsApplied() || s.GroupID != 1 || s.Failure != nil {
+		t.Errorf("got %+v, want 20261001000000 applied in group 1", s)
+	}
+	if s := statuses[1]; s.IsApplied() || s.Failure == nil || s.Failure.Direction != "up" || s.Failure.Statement != 3 {
+		t.Errorf("got %+v, want 20261002000000 unapplied with a failure at statement 3", s)
+	}
+	if len(ran) != 0 {
+		t.Errorf("Status ran %q", ran)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	rec.On(`FOR UPDATE NOWAIT`).Fail(orafake.OraError(54, "resource busy and acquire with NOWAIT specified"))
+
+	m := oramigrate.NewMigrator(db, migrate.NewMigrations())
+	if _, err := m.Up(context.Background()); !errors.Is(err, oramigrate.ErrLocked) {
+		t.Errorf("Up: got %v, want ErrLocked", err)
+	}
+	if _, err := m.Down(context.Background()); !errors.Is(err, oramigrate.ErrLocked) {
+		t.Errorf("Down: got %v, want ErrLocked", err)
+	}
+}
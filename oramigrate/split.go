@@ -0,0 +1,234 @@
+package oramigrate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// SplitStatements splits an Oracle SQL script into statements that can be
+// sent to the server one at a time.
+//
+// Plain SQL statements end with ';', which is stripped. PL/SQL units
+// (anonymous blocks and CREATE PROCEDURE, FUNCTION, PACKAGE, TRIGGER and
+// TYPE) contain semicolons of their own and end with a line holding a
+// single '/', as in SQL*Plus; their final ';' is kept. A '/' line also ends
+// a plain statement, and the bun "--bun:split" directive is honoured.
+// Semicolons inside quotes, q-quotes such as q'[it's]' and comments are
+// ignored.
+func SplitStatements(r io.Reader) ([]string, error) {
+	var stmts []string
+	var cur strings.Builder
+	var st scanState
+
+	flush := func(keepSemicolon bool) {
+		stmt := strings.TrimSpace(cur.String())
+		if !keepSemicolon {
+			stmt = strings.TrimSpace(strings.TrimSuffix(stmt, ";"))
+		}
+		if stmt != "" && !onlyComments(stmt) {
+			stmts = append(stmts, stmt)
+		}
+		cur.Reset()
+		st = scanState{}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if !st.inQuote && !st.inBlockComment {
+			if trimmed == "/" {
+				flush(isPLSQL(cur.String()))
+				continue
+			}
+			if strings.HasPrefix(trimmed, "--bun:") {
+				if trimmed != "--bun:split" {
+					return nil, fmt.Errorf("oramigrate: unknown directive: %q", trimmed)
+				}
+				flush(isPLSQL(cur.String()))
+				continue
+			}
+		}
+
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			cur.WriteByte(c)
+			switch {
+			case st.inBlockComment:
+				if c == '*' && i+1 < len(line) && line[i+1] == '/' {
+					cur.WriteByte('/')
+					i++
+					st.inBlockComment = false
+				}
+			case st.inQuote && st.qClose != 0:
+				if c == st.qClose && i+1 < len(line) && line[i+1] == '\'' {
+					cur.WriteByte('\'')
+					i++
+					st.inQuote, st.qClose = false, 0
+				}
+			case st.inQuote:
+				if c == st.quote {
+					st.inQuote = false
+				}
+			case c == '-' && i+1 < len(line) && line[i+1] == '-':
+				cur.WriteString(line[i+1:])
+				i = len(line)
+			case c == '/' && i+1 < len(line) && line[i+1] == '*':
+				cur.WriteByte('*')
+				i++
+				st.inBlockComment = true
+			case c == '\'' && isQQuote(line, i):
+				// q'[...]' ends at the closing delimiter followed by a
+				// quote, so it may hold quotes of its own.
+				st.inQuote, st.quote, st.qClose = true, c, qClosing(line[i+1])
+				cur.WriteByte(line[i+1])
+				i++
+			case c == '\'' || c == '"':
+				st.inQuote, st.quote = true, c
+			case c == ';':
+				if !isPLSQL(cur.String()) {
+					flush(false)
+				}
+			}
+		}
+		cur.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if st.inQuote || st.inBlockComment {
+		return nil, fmt.Errorf("oramigrate: unterminated quote or comment at end of script")
+	}
+	flush(isPLSQL(cur.String()))
+	return stmts, nil
+}
+
+type scanState struct {
+	inQuote        bool
+	quote          byte
+	qClose         byte // closing delimiter of a q-quote, or 0
+	inBlockComment bool
+}
+
+// isQQuote reports whether the quote at line[i] opens a q-quoted literal,
+// q'<delimiter>...<delimiter>' or nq'...', rather than ending an identifier
+// such as a column named q.
+func isQQuote(line string, i int) bool {
+	if i == 0 || i+1 >= len(line) || (line[i-1] != 'q' && line[i-1] != 'Q') {
+		return false
+	}
+	if i >= 2 {
+		prev := line[i-2]
+		if prev == 'n' || prev == 'N' {
+			return i == 2 || !isIdentByte(line[i-3])
+		}
+		if isIdentByte(prev) {
+			return false
+		}
+	}
+	d := line[i+1]
+	return d != ' ' && d != '\t' && d != '\''
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || c == '"' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// qClosing returns the delimiter closing a q-quote opened with d.
+func qClosing(d byte) byte {
+	switch d {
+	case '[':
+		return ']'
+	case '(':
+		return ')'
+	case '{':
+		return '}'
+	case '<':
+		return '>'
+	}
+	return d
+}
+
+var plsqlUnits = map[string]bool{
+	"PROCEDURE": true,
+	"FUNCTION":  true,
+	"PACKAGE":   true,
+	"TRIGGER":   true,
+	"TYPE":      true,
+	"LIBRARY":   true,
+}
+
+// isPLSQL reports whether stmt starts a PL/SQL unit that must be terminated
+// by a '/' line rather than by ';'.
+func isPLSQL(stmt string) bool {
+	words := leadingWords(stmt, 6)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "BEGIN", "DECLARE":
+		return true
+	case "CREATE":
+	default:
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "EDITIONABLE", "NONEDITIONABLE", "EDITIONING", "NONEDITIONING":
+			continue
+		}
+		return plsqlUnits[w]
+	}
+	return false
+}
+
+func onlyComments(stmt string) bool {
+	return len(leadingWords(stmt, 1)) == 0
+}
+
+// leadingWords returns up to n upper-cased words of s, skipping comments and
+// whitespace.
+func leadingWords(s string, n int) []string {
+	var words []string
+	for len(words) < n {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(s, "--"):
+			i := strings.IndexByte(s, '\n')
+			if i < 0 {
+				return words
+			}
+			s = s[i+1:]
+			continue
+		case strings.HasPrefix(s, "/*"):
+			i := strings.Index(s, "*/")
+			if i < 0 {
+				return words
+			}
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '_'
+		})
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			if s == "" {
+				return words
+			}
+			// Stop at punctuation, but count it as content.
+			return append(words, s[:1])
+		}
+		words = append(words, strings.ToUpper(s[:end]))
+		s = s[end:]
+	}
+	return words
+}
@@ -0,0 +1,111 @@
+package oramigrate_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oramigrate"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			"plain statements",
+			"CREATE TABLE a (id NUMBER);\nINSERT INTO a VALUES (1);\n",
+			[]string{"CREATE TABLE a (id NUMBER)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			"semicolons in quotes",
+			"INSERT INTO a VALUES ('x;y', 'it''s;');\nSELECT \"a;b\" FROM dual;\n",
+			[]string{"INSERT INTO a VALUES ('x;y', 'it''s;')", `SELECT "a;b" FROM dual`},
+		},
+		{
+			"q-quotes",
+			"INSERT INTO a VALUES (q'[it's; here]', Q'{x;'}', nq'!a';b!', q'<;>');\nSELECT 1 FROM dual;\n",
+			[]string{"INSERT INTO a VALUES (q'[it's; here]', Q'{x;'}', nq'!a';b!', q'<;>')", "SELECT 1 FROM dual"},
+		},
+		{
+			"q-quote across lines",
+			"INSERT INTO a VALUES (q'(one;\ntwo')');\n",
+			[]string{"INSERT INTO a VALUES (q'(one;\ntwo')')"},
+		},
+		{
+			"identifier ending in q",
+			"SELECT seq'y;' FROM t;\nSELECT q '[;' FROM t;\n",
+			[]string{"SELECT seq'y;' FROM t", "SELECT q '[;' FROM t"},
+		},
+		{
+			"comments",
+			"-- drop it; later\nDROP TABLE a; -- done;\n/* one; two */ DROP TABLE b;\n/*\n;\n*/\n",
+			// A comment after ';' leads the next statement.
+			[]string{"-- drop it; later\nDROP TABLE a", "-- done;\n/* one; two */ DROP TABLE b"},
+		},
+		{
+			"slash ends a plain statement",
+			"UPDATE a SET id = 2\n/\nUPDATE a SET id = 3\n  /  \n",
+			[]string{"UPDATE a SET id = 2", "UPDATE a SET id = 3"},
+		},
+		{
+			"anonymous block",
+			"BEGIN\n  NULL;\nEND;\n/\nDECLARE\n  n NUMBER;\nBEGIN\n  n := 1;\nEND;\n/\n",
+			[]string{"BEGIN\n  NULL;\nEND;", "DECLARE\n  n NUMBER;\nBEGIN\n  n := 1;\nEND;"},
+		},
+		{
+			"package body",
+			"CREATE OR REPLACE PACKAGE BODY p AS\n  PROCEDURE run IS\n  BEGIN\n    DELETE FROM a WHERE note = 'x;';\n  END;\nEND p;\n/\nGRANT EXECUTE ON p TO app;\n",
+			[]string{
+				"CREATE OR REPLACE PACKAGE BODY p AS\n  PROCEDURE run IS\n  BEGIN\n    DELETE FROM a WHERE note = 'x;';\n  END;\nEND p;",
+				"GRANT EXECUTE ON p TO app",
+			},
+		},
+		{
+			"trigger",
+			"CREATE OR REPLACE EDITIONABLE TRIGGER a_bi\nBEFORE INSERT ON a FOR EACH ROW\nBEGIN\n  :new.id := a_seq.NEXTVAL;\nEND;\n/\n",
+			[]string{"CREATE OR REPLACE EDITIONABLE TRIGGER a_bi\nBEFORE INSERT ON a FOR EACH ROW\nBEGIN\n  :new.id := a_seq.NEXTVAL;\nEND;"},
+		},
+		{
+			"bun split",
+			"CREATE PROCEDURE p IS\nBEGIN\n  NULL;\nEND;\n--bun:split\nCREATE TABLE a (id NUMBER)\n--bun:split\nDROP TABLE b\n",
+			[]string{"CREATE PROCEDURE p IS\nBEGIN\n  NULL;\nEND;", "CREATE TABLE a (id NUMBER)", "DROP TABLE b"},
+		},
+		{
+			"unterminated last statement",
+			"SELECT 1 FROM dual",
+			[]string{"SELECT 1 FROM dual"},
+		},
+		{
+			"only comments",
+			"-- nothing to do\n/* at all */\n",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oramigrate.SplitStatements(strings.NewReader(tt.script))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStatementsErrors(t *testing.T) {
+	for _, script := range []string{
+		"SELECT 'open FROM dual;",
+		"SELECT q'[open' FROM dual;",
+		"/* open\nSELECT 1 FROM dual;",
+		"--bun:nosplit\nSELECT 1 FROM dual;",
+	} {
+		if got, err := oramigrate.SplitStatements(strings.NewReader(script)); err == nil {
+			t.Errorf("%q: got %q, want an error", script, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package oramigrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+// StatementError reports which statement of a SQL migration failed. Oracle
+// commits DDL implicitly, so statements before Index have been applied and
+// are not undone.
+type StatementError struct {
+	// Index is the 1-based position of the failed statement.
+	Index     int
+	Statement string
+	Err       error
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("statement %d failed: %v\n%s", e.Index, e.Err, e.Statement)
+}
+
+func (e *StatementError) Unwrap() error {
+	return e.Err
+}
+
+// NewSQLMigrationFunc returns a migration running the statements of the
+// file name in fsys, split with SplitStatements. Files ending in
+// .tx.up.sql or .tx.down.sql run inside a transaction, which only helps for
+// DML: Oracle commits around every DDL statement regardless.
+func NewSQLMigrationFunc(fsys fs.FS, name string) migrate.MigrationFunc {
+	return func(ctx context.Context, db *bun.DB) error {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		stmts, err := SplitStatements(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if strings.HasSuffix(name, ".tx.up.sql") || strings.HasSuffix(name, ".tx.down.sql") {
+			return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				return execStatements(ctx, tx, stmts)
+			})
+		}
+
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return execStatements(ctx, conn, stmts)
+	}
+}
+
+func execStatements(ctx context.Context, conn bun.IConn, stmts []string) error {
+	for i, stmt := range stmts {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return &StatementError{Index: i + 1, Statement: stmt, Err: err}
+		}
+	}
+	return nil
+}
+
+var fileRE = regexp.MustCompile(`^(\d{1,14})_([0-9a-z_\-]+)\.(tx\.)?(up|down)\.sql$`)
+
+// Discover registers the SQL migrations found in the root of fsys with
+// migrations, using NewSQLMigrationFunc instead of bun's splitter. Files are
+// named like 20250102150405_create_products.up.sql and
+// 20250102150405_create_products.down.sql.
+func Discover(migrations *migrate.Migrations, fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]*migrate.Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileRE.FindStringSubmatch(entry.Name())
+		if match == nil {
+			if path.Ext(entry.Name()) == ".sql" {
+				return fmt.Errorf("oramigrate: unexpected migration file name: %s", entry.Name())
+			}
+			continue
+		}
+		name, comment, direction := match[1], match[2], match[4]
+
+		m, ok := found[name]
+		if !ok {
+			m = &migrate.Migration{Name: name, Comment: comment}
+			found[name] = m
+		}
+		fn := NewSQLMigrationFunc(fsys, entry.Name())
+		if direction == "up" {
+			m.Up = fn
+		} else {
+			m.Down = fn
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		migrations.Add(*found[name])
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package oranum maps Oracle NUMBER values to Go without going through
+// binary floating point.
+package oranum
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/uptrace/bun/schema"
+)
+
+// Decimal is an exact decimal number: coef × 10^-scale. The zero value is
+// 0. Decimals are immutable; operations return new values.
+//
+// go-ora returns NUMBER columns as decimal strings, which Scan parses
+// without loss. bun writes a Decimal as a numeric literal; bound through
+// database/sql it is a string, which Oracle converts with the session's
+// NLS_NUMERIC_CHARACTERS. Declare the column type in the tag, e.g.
+// `bun:",type:number(10,2)"`; without one oraddl creates a plain NUMBER.
+type Decimal struct {
+	coef  *big.Int // nil is 0
+	scale int32    // digits after the decimal point, never negative
+}
+
+var (
+	_ sql.Scanner          = (*Decimal)(nil)
+	_ driver.Valuer        = Decimal{}
+	_ schema.QueryAppender = Decimal{}
+)
+
+// New returns unscaled × 10^-scale, e.g. New(499, 2) is 4.99.
+func New(unscaled int64, scale int32) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+func newDecimal(coef *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		coef = new(big.Int).Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// The scales Parse accepts. NUMBER holds magnitudes from 1e-130 to just
+// below 1e126, so no value it stores needs a scale outside them.
+const (
+	minScale = -126
+	maxScale = 130
+)
+
+// Parse parses a decimal such as "-12.50" or "1.5e-3". It rejects decimals
+// whose scale is outside NUMBER's range, such as "1e999", rather than
+// computing their digits.
+func Parse(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("oranum: invalid decimal %q", s)
+		}
+		mantissa = s[:i]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if strings.TrimLeft(digits, "+-") == "" || strings.ContainsAny(fracPart, "+-") {
+		return Decimal{}, fmt.Errorf("oranum: invalid decimal %q", s)
+	}
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("oranum: invalid decimal %q", s)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale < minScale || scale > maxScale {
+		return Decimal{}, fmt.Errorf("oranum: decimal %q is out of NUMBER's range", s)
+	}
+	return newDecimal(coef, int32(scale)), nil
+}
+
+// MustParse is like Parse but panics on an invalid decimal. It is meant for
+// constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) bigCoef() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d at a scale not lower than its own.
+func (d Decimal) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(d.bigCoef(), pow10(scale-d.scale))
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+func (d Decimal) Sign() int {
+	return d.bigCoef().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares d and e like big.Int.Cmp; 1.5 and 1.50 are equal.
+func (d Decimal) Cmp(e Decimal) int {
+	scale := max(d.scale, e.scale)
+	return d.rescale(scale).Cmp(e.rescale(scale))
+}
+
+func (d Decimal) Add(e Decimal) Decimal {
+	scale := max(d.scale, e.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), e.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(e Decimal) Decimal {
+	scale := max(d.scale, e.scale)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), e.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.bigCoef(), e.bigCoef()), scale: d.scale + e.scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.bigCoef()), scale: d.scale}
+}
+
+// Round returns d with exactly places digits after the decimal point,
+// rounding half away from zero as Oracle's ROUND does.
+func (d Decimal) Round(places int32) Decimal {
+	places = max(places, 0)
+	if places >= d.scale {
+		return Decimal{coef: d.rescale(places), scale: places}
+	}
+	div := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.bigCoef(), div, new(big.Int))
+	twice := new(big.Int).Abs(r)
+	if twice.Lsh(twice, 1).Cmp(div) >= 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{coef: q, scale: places}
+}
+
+// String formats d in plain notation with its scale, e.g. "4.90".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.bigCoef()).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(digits); n > 0 {
+			digits = strings.Repeat("0", n) + digits
+		}
+		i := len(digits) - int(d.scale)
+		digits = digits[:i] + "." + digits[i:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Scan implements sql.Scanner. NULL scans as 0.
+func (d *Decimal) Scan(src any) error {
+	var err error
+	switch src := src.(type) {
+	case nil:
+		*d = Decimal{}
+	case string:
+		*d, err = Parse(src)
+	case []byte:
+		*d, err = Parse(string(src))
+	case int64:
+		*d = New(src, 0)
+	case float64:
+		*d, err = Parse(strconv.FormatFloat(src, 'g', -1, 64))
+	default:
+		err = fmt.Errorf("oranum: cannot scan %T into a Decimal", src)
+	}
+	return err
+}
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// AppendQuery writes d as a numeric literal, which Oracle reads the same
+// whatever the NLS settings.
+func (d Decimal) AppendQuery(_ schema.Formatter, b []byte) ([]byte, error) {
+	return append(b, d.String()...), nil
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,137 @@
+package oranum_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+)
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0", "0"},
+		{"4.99", "4.99"},
+		{"4.90", "4.90"},
+		{"-0.05", "-0.05"},
+		{".5", "0.5"},
+		{"12.", "12"},
+		{"+7", "7"},
+		{"1.5e-3", "0.0015"},
+		{"25e2", "2500"},
+		{"1E+5", "100000"},
+		{"-1.5E2", "-150"},
+		{"1e-130", "0." + strings.Repeat("0", 129) + "1"},
+		{"9.99e125", "999" + strings.Repeat("0", 123)},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+	}
+	for _, tt := range tests {
+		d, err := oranum.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1.2.3", "1e", "abc", "1.-5", "1e5.5"} {
+		if _, err := oranum.Parse(in); err == nil {
+			t.Errorf("Parse(%q): got no error", in)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	// Each of these would take minutes or overflow the scale if Parse
+	// computed its digits.
+	for _, in := range []string{
+		"1e127",
+		"1e-131",
+		"0.5e-130",
+		"1e999999999",
+		"1e-999999999",
+		"1e2147483647",
+		"1e-2147483648",
+		"1e2147483648",
+		"0." + strings.Repeat("0", 130) + "1",
+	} {
+		if d, err := oranum.Parse(in); err == nil {
+			t.Errorf("Parse(%.40q) = %.40s, want an error", in, d)
+		}
+	}
+
+	var d oranum.Decimal
+	if err := d.Scan("1e999999999"); err == nil {
+		t.Error("Scan(1e999999999): got no error")
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	// 0.1 + 0.2 is 0.30000000000000004 in float64.
+	if got := oranum.MustParse("0.1").Add(oranum.MustParse("0.2")); got.Cmp(oranum.MustParse("0.3")) != 0 {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+
+	var total oranum.Decimal
+	for range 1000 {
+		total = total.Add(oranum.MustParse("4.99"))
+	}
+	if got := total.String(); got != "4990.00" {
+		t.Errorf("1000 × 4.99 = %s, want 4990.00", got)
+	}
+
+	if got := oranum.MustParse("19.99").Mul(oranum.New(3, 0)).Sub(oranum.MustParse("0.97")); got.String() != "59.00" {
+		t.Errorf("19.99 × 3 - 0.97 = %s, want 59.00", got)
+	}
+	if got := oranum.MustParse("-1.50").Neg(); got.Cmp(oranum.MustParse("1.5")) != 0 || got.Sign() != 1 {
+		t.Errorf("-(-1.50) = %s", got)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"4.9", 2, "4.90"},
+		{"2.345", 2, "2.35"},
+		{"2.344", 2, "2.34"},
+		{"-2.345", 2, "-2.35"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"-0.004", 2, "0.00"},
+	}
+	for _, tt := range tests {
+		if got := oranum.MustParse(tt.in).Round(tt.places).String(); got != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestScanValue(t *testing.T) {
+	// go-ora returns NUMBER columns as strings such as these.
+	for _, src := range []any{"4.99", []byte("4.99"), 4.99} {
+		var d oranum.Decimal
+		if err := d.Scan(src); err != nil {
+			t.Fatalf("Scan(%#v): %v", src, err)
+		}
+		if v, _ := d.Value(); v != "4.99" {
+			t.Errorf("Scan(%#v) then Value() = %#v, want \"4.99\"", src, v)
+		}
+	}
+
+	d := oranum.MustParse("1.25")
+	if err := d.Scan(nil); err != nil || !d.IsZero() {
+		t.Errorf("Scan(nil) = %s, %v; want 0", d, err)
+	}
+	if err := d.Scan(int64(42)); err != nil || d.String() != "42" {
+		t.Errorf("Scan(42) = %s, %v", d, err)
+	}
+	if err := d.Scan(true); err == nil {
+		t.Error("Scan(true): got no error")
+	}
+}
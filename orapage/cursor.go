@@ -0,0 +1,133 @@
+package orapage
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+)
+
+// ErrInvalidCursor is returned for cursors that do not decode or that were
+// issued for a different ordering.
+var ErrInvalidCursor = errors.New("orapage: invalid cursor")
+
+// cursor is the decoded form of the opaque cursor strings. Values keep
+// their types, so that they compare against the columns without relying on
+// implicit conversions and NLS settings; oranum.Decimal values are "d",
+// oratime.Time values "z" and oratime.Duration values "p".
+type cursor struct {
+	// Before is set for cursors pointing backwards, from the first row of a
+	// page.
+	Before  bool     `json:"b,omitempty"`
+	Columns []string `json:"c"`
+	Values  []value  `json:"v"`
+}
+
+type value struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+func (c *cursor) encode() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := new(cursor)
+	if err := json.Unmarshal(b, c); err != nil || len(c.Columns) != len(c.Values) {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// encodeTyped encodes the oranum and oratime values, which bun writes as
+// literals of their own and which database/sql cannot convert, as
+// themselves. It reports false for other values.
+func encodeTyped(v any) (value, bool) {
+	switch v := v.(type) {
+	case oranum.Decimal:
+		return value{Type: "d", Value: v.String()}, true
+	case oratime.Time:
+		if v.IsZero() {
+			return value{Type: "n"}, true
+		}
+		return value{Type: "z", Value: v.Format(time.RFC3339Nano)}, true
+	case oratime.Duration:
+		return value{Type: "p", Value: strconv.FormatInt(int64(v.Duration), 10)}, true
+	}
+	return value{}, false
+}
+
+func encodeValue(v driver.Value) (value, error) {
+	switch v := v.(type) {
+	case nil:
+		return value{Type: "n"}, nil
+	case int64:
+		return value{Type: "i", Value: strconv.FormatInt(v, 10)}, nil
+	case float64:
+		return value{Type: "f", Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case bool:
+		return value{Type: "b", Value: strconv.FormatBool(v)}, nil
+	case string:
+		return value{Type: "s", Value: v}, nil
+	case []byte:
+		return value{Type: "x", Value: hex.EncodeToString(v)}, nil
+	case time.Time:
+		return value{Type: "t", Value: v.Format(time.RFC3339Nano)}, nil
+	}
+	return value{}, fmt.Errorf("orapage: cannot encode %T in a cursor", v)
+}
+
+func (v value) decode() (any, error) {
+	var (
+		out any
+		err error
+	)
+	switch v.Type {
+	case "n":
+		return nil, nil
+	case "i":
+		out, err = strconv.ParseInt(v.Value, 10, 64)
+	case "f":
+		out, err = strconv.ParseFloat(v.Value, 64)
+	case "b":
+		out, err = strconv.ParseBool(v.Value)
+	case "d":
+		out, err = oranum.Parse(v.Value)
+	case "z":
+		var t time.Time
+		t, err = time.Parse(time.RFC3339Nano, v.Value)
+		out = oratime.Time{Time: t}
+	case "p":
+		var n int64
+		n, err = strconv.ParseInt(v.Value, 10, 64)
+		out = oratime.Duration{Duration: time.Duration(n)}
+	case "s":
+		out = v.Value
+	case "x":
+		out, err = hex.DecodeString(v.Value)
+	case "t":
+		out, err = time.Parse(time.RFC3339Nano, v.Value)
+	default:
+		err = ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return out, nil
+}
@@ -0,0 +1,297 @@
+package orapage
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Nulls places NULLs in an ordering.
+type Nulls int
+
+const (
+	// NullsDefault is Oracle's default: NULLs sort as the highest value,
+	// last in ascending and first in descending order.
+	NullsDefault Nulls = iota
+	NullsFirst
+	NullsLast
+)
+
+// Order is one column of a keyset ordering.
+type Order struct {
+	// Column is the column name of a model field.
+	Column string
+	Desc   bool
+	Nulls  Nulls
+}
+
+func (o Order) nullsFirst() bool {
+	switch o.Nulls {
+	case NullsFirst:
+		return true
+	case NullsLast:
+		return false
+	}
+	return o.Desc
+}
+
+// reverse returns the ordering that lists the same rows backwards.
+func (o Order) reverse() Order {
+	r := Order{Column: o.Column, Desc: !o.Desc, Nulls: NullsFirst}
+	if o.nullsFirst() {
+		r.Nulls = NullsLast
+	}
+	return r
+}
+
+// Keyset pages a query by the values of its ordering columns instead of an
+// offset, so that a deep page costs as much as the first one given an index
+// on the ordering.
+type Keyset struct {
+	// Order is the ordering of the pages. The primary key is appended when
+	// missing, so that rows with equal values are neither skipped nor
+	// repeated.
+	Order []Order
+	// Limit is the page size.
+	Limit int
+}
+
+// Page holds the cursors around a fetched page. A cursor is empty when
+// there is nothing in that direction.
+type Page struct {
+	Next string
+	Prev string
+}
+
+// Fetch loads the page at cursor, or the first page when cursor is empty,
+// into the model of q, which must be a pointer to a slice of structs. q must
+// not be ordered or limited already.
+func (k *Keyset) Fetch(ctx context.Context, db bun.IDB, q *bun.SelectQuery, cursor string) (*Page, error) {
+	if k.Limit <= 0 {
+		return nil, fmt.Errorf("orapage: Limit must be positive")
+	}
+	dest := q.GetModel().Value()
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("orapage: model must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	table := db.Dialect().Tables().Get(elemType)
+
+	orders, err := k.orders(table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(orders))
+	exprs := make([]string, len(orders))
+	notNull := make([]bool, len(orders))
+	for i, o := range orders {
+		f := table.FieldMap[o.Column]
+		columns[i] = o.Column
+		exprs[i] = string(table.SQLAlias) + "." + string(f.SQLName)
+		notNull[i] = f.IsPK || f.NotNull
+	}
+
+	var (
+		before bool
+		values []any
+	)
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Equal(c.Columns, columns) {
+			return nil, ErrInvalidCursor
+		}
+		before = c.Before
+		for _, v := range c.Values {
+			dv, err := v.decode()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, dv)
+		}
+	}
+
+	if before {
+		for i := range orders {
+			orders[i] = orders[i].reverse()
+		}
+	}
+	if values != nil {
+		pred, args := predicate(exprs, notNull, orders, values)
+		q = q.Where(pred, args...)
+	}
+	for i, o := range orders {
+		dir, nulls := "ASC", "NULLS LAST"
+		if o.Desc {
+			dir = "DESC"
+		}
+		if o.nullsFirst() {
+			nulls = "NULLS FIRST"
+		}
+		q = q.OrderExpr(exprs[i] + " " + dir + " " + nulls)
+	}
+
+	if err := db.NewRaw("? FETCH FIRST ? ROWS ONLY", q, k.Limit+1).Scan(ctx, dest); err != nil {
+		return nil, err
+	}
+
+	more := slice.Len() > k.Limit
+	if more {
+		slice.Set(slice.Slice(0, k.Limit))
+	}
+	if before {
+		reverse(slice)
+	}
+
+	page := new(Page)
+	n := slice.Len()
+	if n == 0 {
+		return page, nil
+	}
+	hasNext, hasPrev := more, cursor != ""
+	if before {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		if page.Next, err = rowCursor(table, columns, slice.Index(n-1), false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if page.Prev, err = rowCursor(table, columns, slice.Index(0), true); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func (k *Keyset) orders(table *schema.Table) ([]Order, error) {
+	orders := slices.Clone(k.Order)
+	for _, o := range orders {
+		if _, ok := table.FieldMap[o.Column]; !ok {
+			return nil, fmt.Errorf("orapage: %s has no column %q", table.TypeName, o.Column)
+		}
+	}
+	for _, pk := range table.PKs {
+		if !slices.ContainsFunc(orders, func(o Order) bool { return o.Column == pk.Name }) {
+			orders = append(orders, Order{Column: pk.Name})
+		}
+	}
+	if len(table.PKs) == 0 {
+		return nil, fmt.Errorf("orapage: %s has no primary key to break ties", table.TypeName)
+	}
+	return orders, nil
+}
+
+// predicate selects the rows that come after values in the given ordering:
+// those equal on the first i-1 columns and after the value of column i, for
+// some i. Equality on NULL is IS NULL, and whether NULLs come after a value
+// depends on where the ordering puts them. Columns that are NOT NULL skip
+// the NULL cases, which keeps the predicate simple enough for an index.
+func predicate(exprs []string, notNull []bool, orders []Order, values []any) (string, []any) {
+	var (
+		ors  []string
+		args []any
+	)
+	for i, o := range orders {
+		after, afterArgs, ok := afterExpr(exprs[i], notNull[i], o, values[i])
+		if !ok {
+			continue
+		}
+		var ands []string
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				ands = append(ands, exprs[j]+" IS NULL")
+			} else {
+				ands = append(ands, exprs[j]+" = ?")
+				args = append(args, arg(values[j]))
+			}
+		}
+		ands = append(ands, after)
+		args = append(args, afterArgs...)
+		if len(ands) == 1 {
+			ors = append(ors, after)
+		} else {
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+	}
+	if len(ors) == 0 {
+		return "1 = 0", nil
+	}
+	return strings.Join(ors, " OR "), args
+}
+
+func afterExpr(expr string, notNull bool, o Order, v any) (string, []any, bool) {
+	if v == nil {
+		if o.nullsFirst() {
+			return expr + " IS NOT NULL", nil, true
+		}
+		return "", nil, false
+	}
+	op := " > ?"
+	if o.Desc {
+		op = " < ?"
+	}
+	if notNull || o.nullsFirst() {
+		return "(" + expr + op + ")", []any{arg(v)}, true
+	}
+	return "(" + expr + op + " OR " + expr + " IS NULL)", []any{arg(v)}, true
+}
+
+// arg binds v; bun formats []byte as a 0x literal, which Oracle rejects.
+func arg(v any) any {
+	if b, ok := v.([]byte); ok {
+		return bun.SafeQuery("HEXTORAW(?)", hex.EncodeToString(b))
+	}
+	return v
+}
+
+func rowCursor(table *schema.Table, columns []string, row reflect.Value, before bool) (string, error) {
+	if row.Kind() == reflect.Ptr {
+		row = row.Elem()
+	}
+	c := &cursor{Before: before, Columns: columns}
+	for _, col := range columns {
+		f := table.FieldMap[col]
+		fv := f.Value(row).Interface()
+		if f.NullZero && f.HasZeroValue(row) {
+			c.Values = append(c.Values, value{Type: "n"})
+			continue
+		}
+		if v, ok := encodeTyped(fv); ok {
+			c.Values = append(c.Values, v)
+			continue
+		}
+		dv, err := driver.DefaultParameterConverter.ConvertValue(fv)
+		if err != nil {
+			return "", fmt.Errorf("orapage: %s.%s: %w", table.TypeName, f.GoName, err)
+		}
+		v, err := encodeValue(dv)
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, v)
+	}
+	return c.encode()
+}
+
+func reverse(slice reflect.Value) {
+	swap := reflect.Swapper(slice.Interface())
+	for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
@@ -0,0 +1,187 @@
+package orapage_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/orapage"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+)
+
+type item struct {
+	bun.BaseModel `bun:"table:items,alias:i"`
+
+	ID    int64 `bun:",pk"`
+	Price float64
+	Name  sql.NullString
+}
+
+var columns = []string{"id", "price", "name"}
+
+func TestKeyset(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	defer db.Close()
+	ctx := context.Background()
+
+	k := &orapage.Keyset{
+		Order: []orapage.Order{
+			{Column: "price", Desc: true},
+			{Column: "name", Nulls: orapage.NullsFirst},
+		},
+		Limit: 2,
+	}
+
+	// First page: one row more than the limit means there is a next page.
+	rec.On(`.`).Times(1).Return(columns,
+		[]any{1, 9.5, "b"},
+		[]any{2, 9.5, nil},
+		[]any{3, 7.0, "a"},
+	)
+	var items []item
+	page, err := k.Fetch(ctx, db, db.NewSelect().Model(&items), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].ID != 2 || page.Next == "" || page.Prev != "" {
+		t.Fatalf("first page: got %+v, %+v", items, page)
+	}
+
+	// The next page starts after (9.5, NULL, 2).
+	rec.On(`.`).Times(1).Return(columns, []any{3, 7.0, "a"})
+	items = nil
+	page, err = k.Fetch(ctx, db, db.NewSelect().Model(&items), page.Next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != 3 || page.Next != "" || page.Prev == "" {
+		t.Fatalf("second page: got %+v, %+v", items, page)
+	}
+
+	// Going back from (7.0, 'a', 3) reverses the ordering and the rows.
+	rec.On(`.`).Times(1).Return(columns,
+		[]any{2, 9.5, nil},
+		[]any{1, 9.5, "b"},
+	)
+	items = nil
+	page, err = k.Fetch(ctx, db, db.NewSelect().Model(&items), page.Prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != 1 || items[1].ID != 2 || page.Next == "" || page.Prev != "" {
+		t.Fatalf("previous page: got %+v, %+v", items, page)
+	}
+
+	want := []string{
+		`SELECT "i"."id", "i"."price", "i"."name" FROM "items" "i" ` +
+			`ORDER BY "i"."price" DESC NULLS FIRST, "i"."name" ASC NULLS FIRST, "i"."id" ASC NULLS LAST ` +
+			`FETCH FIRST 3 ROWS ONLY`,
+		`SELECT "i"."id", "i"."price", "i"."name" FROM "items" "i" WHERE (` +
+			`("i"."price" < 9.5) OR ` +
+			`("i"."price" = 9.5 AND "i"."name" IS NOT NULL) OR ` +
+			`("i"."price" = 9.5 AND "i"."name" IS NULL AND ("i"."id" > 2))) ` +
+			`ORDER BY "i"."price" DESC NULLS FIRST, "i"."name" ASC NULLS FIRST, "i"."id" ASC NULLS LAST ` +
+			`FETCH FIRST 3 ROWS ONLY`,
+		`SELECT "i"."id", "i"."price", "i"."name" FROM "items" "i" WHERE (` +
+			`("i"."price" > 7 OR "i"."price" IS NULL) OR ` +
+			`("i"."price" = 7 AND ("i"."name" < 'a' OR "i"."name" IS NULL)) OR ` +
+			`("i"."price" = 7 AND "i"."name" = 'a' AND ("i"."id" < 3))) ` +
+			`ORDER BY "i"."price" ASC NULLS LAST, "i"."name" DESC NULLS LAST, "i"."id" DESC NULLS FIRST ` +
+			`FETCH FIRST 3 ROWS ONLY`,
+	}
+	got := rec.Queries()
+	if len(got) != len(want) {
+		t.Fatalf("got %d queries, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("query %d:\ngot  %s\nwant %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestKeysetRejectsForeignCursor(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	defer db.Close()
+	ctx := context.Background()
+
+	rec.On(`.`).Return(columns, []any{1, 1.0, "a"}, []any{2, 2.0, "b"})
+	byPrice := &orapage.Keyset{Order: []orapage.Order{{Column: "price"}}, Limit: 1}
+	var items []item
+	page, err := byPrice.Fetch(ctx, db, db.NewSelect().Model(&items), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := &orapage.Keyset{Order: []orapage.Order{{Column: "name"}}, Limit: 1}
+	if _, err := byName.Fetch(ctx, db, db.NewSelect().Model(&items), page.Next); err != orapage.ErrInvalidCursor {
+		t.Errorf("got %v, want ErrInvalidCursor", err)
+	}
+	if _, err := byName.Fetch(ctx, db, db.NewSelect().Model(&items), "not a cursor"); err != orapage.ErrInvalidCursor {
+		t.Errorf("got %v, want ErrInvalidCursor", err)
+	}
+
+	// A forged decimal is parsed within NUMBER's range, not expanded.
+	forged := base64.RawURLEncoding.EncodeToString(
+		[]byte(`{"c":["price","id"],"v":[{"t":"d","v":"1e999999999"},{"t":"i","v":"1"}]}`))
+	if _, err := byPrice.Fetch(ctx, db, db.NewSelect().Model(&items), forged); err != orapage.ErrInvalidCursor {
+		t.Errorf("got %v, want ErrInvalidCursor", err)
+	}
+}
+
+type event struct {
+	bun.BaseModel `bun:"table:events,alias:e"`
+
+	ID   int64        `bun:",pk"`
+	At   oratime.Time `bun:",notnull"`
+	Took oratime.Duration
+}
+
+func TestKeysetTimeColumns(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	defer db.Close()
+	ctx := context.Background()
+
+	k := &orapage.Keyset{Order: []orapage.Order{{Column: "at", Desc: true}, {Column: "took"}}, Limit: 1}
+	at := time.Date(2026, 10, 15, 12, 30, 0, 500, time.FixedZone("", 2*3600))
+	rec.On(`.`).Times(1).Return([]string{"id", "at", "took"},
+		[]any{1, at, "+00 00:01:30.000000"},
+		[]any{2, at.Add(-time.Hour), "+00 00:00:10.000000"},
+	)
+	var events []event
+	page, err := k.Fetch(ctx, db, db.NewSelect().Model(&events), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Next == "" {
+		t.Fatal("got no next page")
+	}
+
+	// The cursor keeps the instant with its offset and the interval, so the
+	// next page compares them as such rather than as strings.
+	events = nil
+	if _, err := k.Fetch(ctx, db, db.NewSelect().Model(&events), page.Next); err != nil {
+		t.Fatal(err)
+	}
+	atLit := `FROM_TZ(TIMESTAMP '2026-10-15 12:30:00.0000005', '+02:00')`
+	tookLit := `INTERVAL '+0 00:01:30' DAY(9) TO SECOND(9)`
+	want := `SELECT "e"."id", "e"."at", "e"."took" FROM "events" "e" WHERE ` +
+		`(("e"."at" < ` + atLit + `) OR ` +
+		`("e"."at" = ` + atLit + ` AND ("e"."took" > ` + tookLit + ` OR "e"."took" IS NULL)) OR ` +
+		`("e"."at" = ` + atLit + ` AND "e"."took" = ` + tookLit + ` AND ("e"."id" > 1))) ` +
+		`ORDER BY "e"."at" DESC NULLS FIRST, "e"."took" ASC NULLS LAST, "e"."id" ASC NULLS LAST FETCH FIRST 2 ROWS ONLY`
+	if got := rec.Queries(); len(got) != 2 || got[1] != want {
+		t.Errorf("got %q, want second query\n%s", got, want)
+	}
+}
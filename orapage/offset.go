@@ -0,0 +1,18 @@
+// Package orapage pages bun select queries on Oracle, by offset or by
+// keyset.
+package orapage
+
+import "github.com/uptrace/bun"
+
+// OffsetFetch pages q with OFFSET ... ROWS FETCH NEXT ... ROWS ONLY. bun's
+// Limit and Offset emit LIMIT and OFFSET on Oracle, which it rejects. A
+// limit of 0 means no limit.
+func OffsetFetch(db bun.IDB, q *bun.SelectQuery, offset, limit int) *bun.RawQuery {
+	switch {
+	case limit > 0:
+		return db.NewRaw("? OFFSET ? ROWS FETCH NEXT ? ROWS ONLY", q, offset, limit)
+	case offset > 0:
+		return db.NewRaw("? OFFSET ? ROWS", q, offset)
+	}
+	return db.NewRaw("?", q)
+}
@@ -0,0 +1,133 @@
+// Package oraretry re-runs bun transactions and queries that failed with a
+// transient Oracle error such as a deadlock, a serialization failure or a
+// lost connection.
+//
+// Selects are retried by Scan rather than by a bun query hook: a hook sees
+// a query only after it has run and cannot run it again or replace the
+// result its caller gets, so wrapping the call is the only place a retry
+// can happen.
+package oraretry
+
+import (
+	"context"
+	"database/sql"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/uptrace/bun"
+)
+
+type config struct {
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	retryable   func(error) bool
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		maxAttempts: 3,
+		minBackoff:  50 * time.Millisecond,
+		maxBackoff:  2 * time.Second,
+		retryable:   oraerr.IsRetryable,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Option configures Do, RunInTx and Scan.
+type Option func(c *config)
+
+// WithMaxAttempts sets how many times the operation is run in total,
+// including the first attempt. The default is 3.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = max(n, 1)
+	}
+}
+
+// WithBackoff sets the bounds of the jittered exponential backoff between
+// attempts. The defaults are 50ms and 2s.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(c *config) {
+		c.minBackoff = minBackoff
+		c.maxBackoff = max(minBackoff, maxBackoff)
+	}
+}
+
+// WithRetryable replaces oraerr.IsRetryable as the predicate deciding
+// whether a failed attempt is retried.
+func WithRetryable(fn func(error) bool) Option {
+	return func(c *config) {
+		c.retryable = fn
+	}
+}
+
+// Do runs fn until it succeeds, returns a non-retryable error, the attempts
+// are exhausted or ctx is done. The last error is returned classified by
+// oraerr.Classify.
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	c := newConfig(opts)
+
+	var err error
+	for attempt := 0; attempt < c.maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return err
+			}
+		}
+		err = fn(ctx)
+		if err == nil || !c.retryable(err) {
+			break
+		}
+	}
+	return oraerr.Classify(err)
+}
+
+// RunInTx is bun.DB.RunInTx that starts the whole transaction again when it
+// fails with a retryable error. fn may therefore be called more than once
+// and must not have side effects outside the transaction.
+func RunInTx(
+	ctx context.Context,
+	db *bun.DB,
+	txOpts *sql.TxOptions,
+	fn func(ctx context.Context, tx bun.Tx) error,
+	opts ...Option,
+) error {
+	return Do(ctx, func(ctx context.Context) error {
+		return db.RunInTx(ctx, txOpts, fn)
+	}, opts...)
+}
+
+// Scan scans q into its model, re-running the query when it fails with a
+// retryable error. q must be an idempotent SELECT.
+func Scan(ctx context.Context, q *bun.SelectQuery, opts ...Option) error {
+	return Do(ctx, func(ctx context.Context) error {
+		return q.Scan(ctx)
+	}, opts...)
+}
+
+func (c *config) backoff(attempt int) time.Duration {
+	d := c.minBackoff << (attempt - 1)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	// Jitter keeps competing sessions from retrying in lockstep, which
+	// would just recreate the same deadlock.
+	return c.minBackoff/2 + rand.N(d-c.minBackoff/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+package oraretry_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oraretry"
+	"github.com/uptrace/bun"
+)
+
+var fast = oraretry.WithBackoff(time.Millisecond, time.Millisecond)
+
+func offlineDB(t *testing.T) (*bun.DB, *orafake.Recorder) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  int
+		kind  error
+		calls int
+	}{
+		{"ORA-00060", oraerr.CodeDeadlock, oraerr.ErrDeadlock, 2},
+		{"ORA-08177", oraerr.CodeSerializationFailure, oraerr.ErrSerializationFailure, 2},
+		{"ORA-03113", oraerr.CodeEndOfFileOnChannel, oraerr.ErrConnectionLost, 2},
+		{"ORA-00001", oraerr.CodeUniqueViolation, oraerr.ErrUniqueViolation, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := oraretry.Do(context.Background(), func(context.Context) error {
+				calls++
+				if calls == 1 {
+					return orafake.OraError(tt.code, "failed")
+				}
+				return nil
+			}, fast)
+			if calls != tt.calls {
+				t.Errorf("got %d calls, want %d", calls, tt.calls)
+			}
+			if tt.calls == 1 && !errors.Is(err, tt.kind) {
+				t.Errorf("got %v, want %v", err, tt.kind)
+			}
+			if tt.calls > 1 && err != nil {
+				t.Errorf("got %v, want success after a retry", err)
+			}
+		})
+	}
+}
+
+func TestDoMaxAttempts(t *testing.T) {
+	calls := 0
+	err := oraretry.Do(context.Background(), func(context.Context) error {
+		calls++
+		if calls == 4 {
+			return orafake.OraError(oraerr.CodeSerializationFailure, "last")
+		}
+		return orafake.OraError(oraerr.CodeDeadlock, "deadlock")
+	}, oraretry.WithMaxAttempts(4), fast)
+	if calls != 4 {
+		t.Errorf("got %d calls, want 4", calls)
+	}
+	if !errors.Is(err, oraerr.ErrSerializationFailure) {
+		t.Errorf("got %v, want the error of the last attempt", err)
+	}
+}
+
+func TestDoCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	start := time.Now()
+	err := oraretry.Do(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return orafake.OraError(oraerr.CodeDeadlock, "deadlock")
+	}, oraretry.WithBackoff(time.Hour, time.Hour))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+	if d := time.Since(start); d > time.Minute {
+		t.Errorf("waited %v after cancel", d)
+	}
+}
+
+func TestRunInTx(t *testing.T) {
+	db, rec := offlineDB(t)
+	rec.On(`^UPDATE`).Times(1).Fail(orafake.OraError(oraerr.CodeDeadlock, "deadlock"))
+
+	calls := 0
+	err := oraretry.RunInTx(context.Background(), db, nil, func(ctx context.Context, tx bun.Tx) error {
+		calls++
+		_, err := tx.ExecContext(ctx, "UPDATE products SET price = 1")
+		return err
+	}, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+
+	want := []string{
+		orafake.Begin, "UPDATE products SET price = 1", orafake.Rollback,
+		orafake.Begin, "UPDATE products SET price = 1", orafake.Commit,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestScan(t *testing.T) {
+	db, rec := offlineDB(t)
+	rec.On(`^SELECT`).Times(1).Fail(orafake.OraError(oraerr.CodeEndOfFileOnChannel, "end-of-file"))
+	rec.On(`^SELECT`).Return([]string{"id", "name"}, []any{42, "pear"})
+
+	p := new(models.Product)
+	q := db.NewSelect().Model(p).Column("id", "name").Limit(1)
+	if err := oraretry.Scan(context.Background(), q, fast); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "pear" {
+		t.Errorf("got %q, want %q", p.Name, "pear")
+	}
+	got := rec.Queries()
+	if len(got) != 2 || got[0] != got[1] {
+		t.Errorf("got %q, want the select run twice", got)
+	}
+}
@@ -0,0 +1,253 @@
+// Package oraschema reads table definitions from the Oracle data dictionary
+// and compares them with bun models.
+package oraschema
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/uptrace/bun"
+)
+
+// Table is a table of the current schema as described by the ALL_* views.
+type Table struct {
+	Owner       string
+	Name        string
+	Columns     []*Column
+	PrimaryKey  []string
+	Indexes     []*Index
+	ForeignKeys []*ForeignKey
+}
+
+// Column looks up a column by its exact name.
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// HasUniqueIndex reports whether a unique index covers exactly cols, in any
+// order. Primary keys count, as Oracle enforces them with such an index.
+func (t *Table) HasUniqueIndex(cols []string) bool {
+	want := slices.Sorted(slices.Values(cols))
+	for _, idx := range t.Indexes {
+		if idx.Unique && slices.Equal(want, slices.Sorted(slices.Values(idx.Columns))) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUniqueIndexNamed reports whether the table has a unique index of the
+// given name.
+func (t *Table) HasUniqueIndexNamed(name string) bool {
+	return slices.ContainsFunc(t.Indexes, func(idx *Index) bool {
+		return idx.Unique && idx.Name == name
+	})
+}
+
+type Column struct {
+	Name       string
+	DataType   string
+	DataLength int64
+	CharLength int64
+	Precision  *int64
+	Scale      *int64
+	Nullable   bool
+	Identity   bool
+}
+
+type Index struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// ForeignKey references the primary or unique key of RefTable, which is in
+// the same schema unless RefOwner says otherwise.
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefOwner   string
+	RefTable   string
+	RefColumns []string
+}
+
+type dictTable struct {
+	Owner     string `bun:"owner"`
+	TableName string `bun:"table_name"`
+}
+
+type dictColumn struct {
+	TableName     string `bun:"table_name"`
+	ColumnName    string `bun:"column_name"`
+	DataType      string `bun:"data_type"`
+	DataLength    int64  `bun:"data_length"`
+	CharLength    int64  `bun:"char_length"`
+	DataPrecision *int64 `bun:"data_precision"`
+	DataScale     *int64 `bun:"data_scale"`
+	Nullable      string `bun:"nullable"`
+	Identity      string `bun:"identity_column"`
+}
+
+type dictConsColumn struct {
+	TableName  string `bun:"table_name"`
+	ColumnName string `bun:"column_name"`
+}
+
+type dictFKColumn struct {
+	TableName      string `bun:"table_name"`
+	ConstraintName string `bun:"constraint_name"`
+	ColumnName     string `bun:"column_name"`
+	RefOwner       string `bun:"r_owner"`
+	RefTable       string `bun:"r_table_name"`
+	RefColumn      string `bun:"r_column_name"`
+}
+
+type dictIndexColumn struct {
+	TableName  string `bun:"table_name"`
+	IndexName  string `bun:"index_name"`
+	Uniqueness string `bun:"uniqueness"`
+	ColumnName string `bun:"column_name"`
+}
+
+// ReadTables returns the tables of the session's current schema, keyed by
+// name. That is the login user unless CURRENT_SCHEMA was changed, for
+// example with oraconn.Session. When names is not empty only those tables are
+// read. Names are matched exactly, as bun quotes identifiers.
+func ReadTables(ctx context.Context, db bun.IDB, names ...string) (map[string]*Table, error) {
+	// Each query below filters on its table_name column when names are
+	// given; the filter is its only argument.
+	var args []any
+	filter := func(col string) string {
+		if len(names) == 0 {
+			return ""
+		}
+		return " AND " + col + " IN (?)"
+	}
+	if len(names) > 0 {
+		args = []any{bun.In(names)}
+	}
+
+	var dictTables []dictTable
+	if err := db.NewRaw(`SELECT owner AS "owner", table_name AS "table_name" FROM all_tables
+WHERE owner = `+currentSchema+filter("table_name")+`
+ORDER BY table_name`, args...).Scan(ctx, &dictTables); err != nil {
+		return nil, err
+	}
+	tables := make(map[string]*Table, len(dictTables))
+	for _, t := range dictTables {
+		tables[t.TableName] = &Table{Owner: t.Owner, Name: t.TableName}
+	}
+
+	var columns []dictColumn
+	if err := db.NewRaw(`SELECT table_name AS "table_name", column_name AS "column_name",
+  data_type AS "data_type", data_length AS "data_length", char_length AS "char_length",
+  data_precision AS "data_precision", data_scale AS "data_scale",
+  nullable AS "nullable", identity_column AS "identity_column"
+FROM all_tab_columns
+WHERE owner = `+currentSchema+filter("table_name")+`
+ORDER BY table_name, column_id`, args...).Scan(ctx, &columns); err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		if t := tables[c.TableName]; t != nil {
+			t.Columns = append(t.Columns, &Column{
+				Name:       c.ColumnName,
+				DataType:   c.DataType,
+				DataLength: c.DataLength,
+				CharLength: c.CharLength,
+				Precision:  c.DataPrecision,
+				Scale:      c.DataScale,
+				Nullable:   c.Nullable == "Y",
+				Identity:   c.Identity == "YES",
+			})
+		}
+	}
+
+	var pkColumns []dictConsColumn
+	if err := db.NewRaw(`SELECT c.table_name AS "table_name", cc.column_name AS "column_name"
+FROM all_constraints c
+JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+WHERE c.owner = `+currentSchema+` AND c.constraint_type = 'P'`+filter("c.table_name")+`
+ORDER BY c.table_name, cc.position`, args...).Scan(ctx, &pkColumns); err != nil {
+		return nil, err
+	}
+	for _, c := range pkColumns {
+		if t := tables[c.TableName]; t != nil {
+			t.PrimaryKey = append(t.PrimaryKey, c.ColumnName)
+		}
+	}
+
+	var fkColumns []dictFKColumn
+	if err := db.NewRaw(`SELECT c.table_name AS "table_name", c.constraint_name AS "constraint_name",
+  cc.column_name AS "column_name", r.owner AS "r_owner", r.table_name AS "r_table_name",
+  rc.column_name AS "r_column_name"
+FROM all_constraints c
+JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+JOIN all_constraints r ON r.owner = c.r_owner AND r.constraint_name = c.r_constraint_name
+JOIN all_cons_columns rc ON rc.owner = r.owner AND rc.constraint_name = r.constraint_name
+  AND rc.position = cc.position
+WHERE c.owner = `+currentSchema+` AND c.constraint_type = 'R'`+filter("c.table_name")+`
+ORDER BY c.table_name, c.constraint_name, cc.position`, args...).Scan(ctx, &fkColumns); err != nil {
+		return nil, err
+	}
+	for _, c := range fkColumns {
+		t := tables[c.TableName]
+		if t == nil {
+			continue
+		}
+		n := len(t.ForeignKeys)
+		if n == 0 || t.ForeignKeys[n-1].Name != c.ConstraintName {
+			t.ForeignKeys = append(t.ForeignKeys, &ForeignKey{
+				Name:     c.ConstraintName,
+				RefOwner: c.RefOwner,
+				RefTable: c.RefTable,
+			})
+			n++
+		}
+		fk := t.ForeignKeys[n-1]
+		fk.Columns = append(fk.Columns, c.ColumnName)
+		fk.RefColumns = append(fk.RefColumns, c.RefColumn)
+	}
+
+	var indexColumns []dictIndexColumn
+	if err := db.NewRaw(`SELECT i.table_name AS "table_name", i.index_name AS "index_name",
+  i.uniqueness AS "uniqueness", ic.column_name AS "column_name"
+FROM all_indexes i
+JOIN all_ind_columns ic ON ic.index_owner = i.owner AND ic.index_name = i.index_name
+WHERE i.table_owner = `+currentSchema+filter("i.table_name")+`
+ORDER BY i.table_name, i.index_name, ic.column_position`, args...).Scan(ctx, &indexColumns); err != nil {
+		return nil, err
+	}
+	for _, c := range indexColumns {
+		t := tables[c.TableName]
+		if t == nil {
+			continue
+		}
+		n := len(t.Indexes)
+		if n == 0 || t.Indexes[n-1].Name != c.IndexName {
+			t.Indexes = append(t.Indexes, &Index{Name: c.IndexName, Unique: c.Uniqueness == "UNIQUE"})
+			n++
+		}
+		t.Indexes[n-1].Columns = append(t.Indexes[n-1].Columns, c.ColumnName)
+	}
+
+	return tables, nil
+}
+
+const currentSchema = "SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')"
+
+// SortedNames returns the keys of tables in order.
+func SortedNames(tables map[string]*Table) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,264 @@
+package oraschema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraddl"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Kind classifies a Difference.
+type Kind int
+
+const (
+	MissingTable Kind = iota + 1
+	MissingColumn
+	ExtraColumn
+	TypeMismatch
+	NullabilityMismatch
+	MissingPrimaryKey
+	PrimaryKeyMismatch
+	MissingUniqueIndex
+)
+
+var kindNames = map[Kind]string{
+	MissingTable:        "missing table",
+	MissingColumn:       "missing column",
+	ExtraColumn:         "extra column",
+	TypeMismatch:        "type mismatch",
+	NullabilityMismatch: "nullability mismatch",
+	MissingPrimaryKey:   "missing primary key",
+	PrimaryKeyMismatch:  "primary key mismatch",
+	MissingUniqueIndex:  "missing unique index",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Difference is one way in which the live schema does not match a model.
+type Difference struct {
+	Kind   Kind
+	Table  string
+	Column string
+	// Expected and Actual describe the model and the database side, where
+	// that applies.
+	Expected string
+	Actual   string
+	// Fix is a statement that reconciles the database with the model. It is
+	// meant for review, not for blind execution: dropping an extra column
+	// loses data and adding a NOT NULL column fails on a non-empty table.
+	Fix string
+}
+
+func (d *Difference) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Kind.String())
+	sb.WriteString(": ")
+	sb.WriteString(d.Table)
+	if d.Column != "" {
+		sb.WriteString(".")
+		sb.WriteString(d.Column)
+	}
+	if d.Expected != "" || d.Actual != "" {
+		fmt.Fprintf(&sb, " (model %s, database %s)", orNone(d.Expected), orNone(d.Actual))
+	}
+	return sb.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Compare reads the tables of models from the data dictionary and reports
+// how they differ from the models.
+func Compare(ctx context.Context, db *bun.DB, models ...any) ([]Difference, error) {
+	tables := make([]*schema.Table, len(models))
+	names := make([]string, len(models))
+	for i, model := range models {
+		tables[i] = db.Table(reflect.TypeOf(model))
+		names[i] = tables[i].Name
+	}
+
+	live, err := ReadTables(ctx, db, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []Difference
+	for _, table := range tables {
+		tableDiffs, err := compareTable(db, table, live[table.Name])
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, tableDiffs...)
+	}
+	return diffs, nil
+}
+
+func compareTable(db *bun.DB, model *schema.Table, live *Table) ([]Difference, error) {
+	if live == nil {
+		fix, err := oraddl.CreateTable(db, model.ZeroIface)
+		if err != nil {
+			return nil, err
+		}
+		return []Difference{{Kind: MissingTable, Table: model.Name, Fix: fix}}, nil
+	}
+
+	var diffs []Difference
+	alter := "ALTER TABLE " + string(model.SQLName)
+
+	for _, field := range model.Fields {
+		typ := ModelType(db.Dialect(), field)
+		notNull := field.NotNull || field.IsPK
+		col := live.Column(field.Name)
+
+		if col == nil {
+			diffs = append(diffs, Difference{
+				Kind:     MissingColumn,
+				Table:    model.Name,
+				Column:   field.Name,
+				Expected: typ,
+				Fix:      fmt.Sprintf("%s ADD (%s %s%s)", alter, field.SQLName, typ, notNullClause(notNull)),
+			})
+			continue
+		}
+
+		if actual := col.DictionaryType(); CanonicalType(typ) != CanonicalType(actual) {
+			diffs = append(diffs, Difference{
+				Kind:     TypeMismatch,
+				Table:    model.Name,
+				Column:   field.Name,
+				Expected: typ,
+				Actual:   actual,
+				Fix:      fmt.Sprintf("%s MODIFY (%s %s)", alter, field.SQLName, typ),
+			})
+		}
+
+		// A primary key column is NOT NULL through its constraint; that case
+		// is reported as a missing primary key instead.
+		if notNull == col.Nullable && !(field.IsPK && len(live.PrimaryKey) == 0) {
+			diffs = append(diffs, Difference{
+				Kind:     NullabilityMismatch,
+				Table:    model.Name,
+				Column:   field.Name,
+				Expected: nullability(notNull),
+				Actual:   nullability(!col.Nullable),
+				Fix:      fmt.Sprintf("%s MODIFY (%s %s)", alter, field.SQLName, nullability(notNull)),
+			})
+		}
+	}
+
+	for _, col := range live.Columns {
+		if _, ok := model.FieldMap[col.Name]; !ok {
+			diffs = append(diffs, Difference{
+				Kind:   ExtraColumn,
+				Table:  model.Name,
+				Column: col.Name,
+				Actual: col.DictionaryType(),
+				Fix:    fmt.Sprintf(`%s DROP COLUMN "%s"`, alter, col.Name),
+			})
+		}
+	}
+
+	pk := fieldNames(model.PKs)
+	switch {
+	case len(pk) > 0 && len(live.PrimaryKey) == 0:
+		diffs = append(diffs, Difference{
+			Kind:     MissingPrimaryKey,
+			Table:    model.Name,
+			Expected: strings.Join(pk, ", "),
+			Fix:      fmt.Sprintf("%s ADD PRIMARY KEY (%s)", alter, quoteList(pk)),
+		})
+	case len(pk) > 0 && !slices.Equal(pk, live.PrimaryKey):
+		diffs = append(diffs, Difference{
+			Kind:     PrimaryKeyMismatch,
+			Table:    model.Name,
+			Expected: strings.Join(pk, ", "),
+			Actual:   strings.Join(live.PrimaryKey, ", "),
+			Fix: fmt.Sprintf("%s DROP PRIMARY KEY;\n%s ADD PRIMARY KEY (%s)",
+				alter, alter, quoteList(pk)),
+		})
+	}
+
+	keys := make([]string, 0, len(model.Unique))
+	for key := range model.Unique {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		groups := [][]*schema.Field{model.Unique[key]}
+		if key == "" {
+			// Fields tagged plain ",unique" are each unique on their own.
+			groups = groups[:0]
+			for _, f := range model.Unique[key] {
+				groups = append(groups, []*schema.Field{f})
+			}
+		}
+		for _, group := range groups {
+			cols := fieldNames(group)
+			if model.SoftDeleteField != nil {
+				// The dictionary lists the columns of a function-based
+				// index as hidden SYS_NC columns, so it is found by name.
+				if !live.HasUniqueIndexNamed(oraddl.LiveUniqueIndexName(model, group)) {
+					diffs = append(diffs, Difference{
+						Kind:     MissingUniqueIndex,
+						Table:    model.Name,
+						Expected: strings.Join(cols, ", ") + " of live rows",
+						Fix:      oraddl.LiveUniqueIndex(db, model, group),
+					})
+				}
+				continue
+			}
+			if !live.HasUniqueIndex(cols) {
+				diffs = append(diffs, Difference{
+					Kind:     MissingUniqueIndex,
+					Table:    model.Name,
+					Expected: strings.Join(cols, ", "),
+					Fix:      fmt.Sprintf("%s ADD UNIQUE (%s)", alter, quoteList(cols)),
+				})
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+func fieldNames(fields []*schema.Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = `"` + name + `"`
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func notNullClause(notNull bool) string {
+	if notNull {
+		return " NOT NULL"
+	}
+	return ""
+}
+
+func nullability(notNull bool) string {
+	if notNull {
+		return "NOT NULL"
+	}
+	return "NULL"
+}
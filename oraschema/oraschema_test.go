@@ -0,0 +1,297 @@
+package oraschema_test
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/oraschema"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+	"github.com/uptrace/bun/schema"
+)
+
+func TestCanonicalType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{"int", "INTEGER"},
+		{"BIGINT", "INTEGER"},
+		{"number(*,0)", "INTEGER"},
+		{"NUMBER", "NUMBER"},
+		{"numeric(10)", "NUMBER(10)"},
+		{"NUMBER(10,0)", "NUMBER(10)"},
+		{"decimal(10, 2)", "NUMBER(10,2)"},
+		{"NUMBER(*,2)", "NUMBER(*,2)"},
+		{"double precision", "FLOAT(126)"},
+		{"FLOAT", "FLOAT(126)"},
+		{"REAL", "FLOAT(63)"},
+		{"varchar(20)", "VARCHAR2(20)"},
+		{"VARCHAR2(20 CHAR)", "VARCHAR2(20)"},
+		{"varchar2(20 byte)", "VARCHAR2(20)"},
+		{"nvarchar2(20)", "NVARCHAR2(20)"},
+		{"RAW(16)", "RAW(16)"},
+		{"timestamp", "TIMESTAMP(6)"},
+		{"TIMESTAMP(3)", "TIMESTAMP(3)"},
+		{"timestamp with  time zone", "TIMESTAMP(6) WITH TIME ZONE"},
+		{"TIMESTAMP(9) WITH LOCAL TIME ZONE", "TIMESTAMP(9) WITH LOCAL TIME ZONE"},
+		{"interval day to second", "INTERVAL DAY(2) TO SECOND(6)"},
+		{"INTERVAL DAY(9) TO SECOND(6)", "INTERVAL DAY(9) TO SECOND(6)"},
+		{"interval year to month", "INTERVAL YEAR(2) TO MONTH"},
+		{"blob", "BLOB"},
+		{"DATE", "DATE"},
+	}
+	for _, tt := range tests {
+		if got := oraschema.CanonicalType(tt.typ); got != tt.want {
+			t.Errorf("CanonicalType(%q) = %q, want %q", tt.typ, got, tt.want)
+		}
+	}
+}
+
+type typed struct {
+	ID       int64
+	Name     string
+	Code     string `bun:"type:char(3)"`
+	Price    oranum.Decimal
+	Cost     oranum.Decimal `bun:"type:number(10,2)"`
+	Made     time.Time
+	Sold     oratime.Time
+	Day      oratime.Time `bun:"type:date"`
+	Warranty oratime.Duration
+	Ratio    float64
+	Active   bool
+	Data     []byte
+}
+
+func TestModelType(t *testing.T) {
+	d := oracledialect.New()
+	table := schema.NewTables(d).Get(reflect.TypeOf((*typed)(nil)))
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"id", "INTEGER"},
+		{"name", "VARCHAR2(255)"},
+		{"code", "char(3)"},
+		{"price", "NUMBER"},
+		{"cost", "number(10,2)"},
+		{"made", "TIMESTAMP"},
+		{"sold", "TIMESTAMP WITH TIME ZONE"},
+		{"day", "date"},
+		{"warranty", "INTERVAL DAY(9) TO SECOND(6)"},
+		{"ratio", "DOUBLE PRECISION"},
+		{"active", "number(1,0)"},
+		{"data", "BLOB"},
+	}
+	for _, tt := range tests {
+		if got := oraschema.ModelType(d, table.FieldMap[tt.field]); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+type Widget struct {
+	bun.BaseModel `bun:"table:widgets"`
+
+	ID    int64          `bun:",pk"`
+	Name  string         `bun:",notnull,unique"`
+	Price oranum.Decimal `bun:"type:number(10,2)"`
+	Sold  oratime.Time
+}
+
+type column struct {
+	name      string
+	typ       string
+	length    int
+	precision any
+	scale     any
+	nullable  string
+}
+
+// widgetColumns are the columns of a widgets table matching Widget.
+var widgetColumns = []column{
+	{"id", "NUMBER", 22, nil, 0, "N"},
+	{"name", "VARCHAR2", 255, nil, nil, "N"},
+	{"price", "NUMBER", 22, 10, 2, "Y"},
+	{"sold", "TIMESTAMP(6) WITH TIME ZONE", 13, nil, 6, "Y"},
+}
+
+// live scripts the data dictionary of rec to describe a widgets table
+// with the given columns, primary key and unique indexes on single columns.
+func live(rec *orafake.Recorder, columns []column, pk []string, unique ...string) {
+	if columns == nil {
+		return
+	}
+	rec.On(`FROM all_tables`).Return([]string{"owner", "table_name"}, []any{"APP", "widgets"})
+
+	rows := make([][]any, len(columns))
+	for i, c := range columns {
+		rows[i] = []any{"widgets", c.name, c.typ, c.length, c.length, c.precision, c.scale, c.nullable, "NO"}
+	}
+	rec.On(`FROM all_tab_columns`).Return([]string{
+		"table_name", "column_name", "data_type", "data_length", "char_length",
+		"data_precision", "data_scale", "nullable", "identity_column",
+	}, rows...)
+
+	rows = nil
+	for _, col := range pk {
+		rows = append(rows, []any{"widgets", col})
+	}
+	rec.On(`constraint_type = 'P'`).Return([]string{"table_name", "column_name"}, rows...)
+
+	rows = nil
+	for _, col := range unique {
+		rows = append(rows, []any{"widgets", "widgets_" + col + "_uq", "UNIQUE", col})
+	}
+	rec.On(`FROM all_indexes`).Return([]string{"table_name", "index_name", "uniqueness", "column_name"}, rows...)
+}
+
+// without returns widgetColumns without the named column.
+func without(name string) []column {
+	return slices.DeleteFunc(slices.Clone(widgetColumns), func(c column) bool { return c.name == name })
+}
+
+// with returns widgetColumns with c replacing the column of its name, or
+// added after them.
+func with(c column) []column {
+	cols := slices.Clone(widgetColumns)
+	if i := slices.IndexFunc(cols, func(old column) bool { return old.name == c.name }); i >= 0 {
+		cols[i] = c
+		return cols
+	}
+	return append(cols, c)
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []column
+		pk      []string
+		unique  []string
+		want    []oraschema.Difference
+	}{
+		{
+			name:    "in sync",
+			columns: widgetColumns, pk: []string{"id"}, unique: []string{"name"},
+		},
+		{
+			name: "missing table",
+			want: []oraschema.Difference{{
+				Kind: oraschema.MissingTable, Table: "widgets",
+				Fix: `CREATE TABLE "widgets" (
+  "id" INTEGER NOT NULL,
+  "name" VARCHAR2(255) NOT NULL,
+  "price" number(10,2),
+  "sold" TIMESTAMP WITH TIME ZONE,
+  PRIMARY KEY ("id"),
+  UNIQUE ("name")
+)`,
+			}},
+		},
+		{
+			name:    "missing column",
+			columns: without("sold"), pk: []string{"id"}, unique: []string{"name"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.MissingColumn, Table: "widgets", Column: "sold",
+				Expected: "TIMESTAMP WITH TIME ZONE",
+				Fix:      `ALTER TABLE "widgets" ADD ("sold" TIMESTAMP WITH TIME ZONE)`,
+			}},
+		},
+		{
+			name:    "extra column",
+			columns: with(column{"legacy", "VARCHAR2", 10, nil, nil, "Y"}), pk: []string{"id"}, unique: []string{"name"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.ExtraColumn, Table: "widgets", Column: "legacy",
+				Actual: "VARCHAR2(10)",
+				Fix:    `ALTER TABLE "widgets" DROP COLUMN "legacy"`,
+			}},
+		},
+		{
+			name:    "type mismatch",
+			columns: with(column{"price", "NUMBER", 22, 12, 2, "Y"}), pk: []string{"id"}, unique: []string{"name"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.TypeMismatch, Table: "widgets", Column: "price",
+				Expected: "number(10,2)", Actual: "NUMBER(12,2)",
+				Fix: `ALTER TABLE "widgets" MODIFY ("price" number(10,2))`,
+			}},
+		},
+		{
+			name:    "nullability mismatch",
+			columns: with(column{"name", "VARCHAR2", 255, nil, nil, "Y"}), pk: []string{"id"}, unique: []string{"name"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.NullabilityMismatch, Table: "widgets", Column: "name",
+				Expected: "NOT NULL", Actual: "NULL",
+				Fix: `ALTER TABLE "widgets" MODIFY ("name" NOT NULL)`,
+			}},
+		},
+		{
+			// The primary key column is nullable without its constraint,
+			// which is reported once, as the missing key.
+			name:    "missing primary key",
+			columns: with(column{"id", "NUMBER", 22, nil, 0, "Y"}), unique: []string{"name"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.MissingPrimaryKey, Table: "widgets", Expected: "id",
+				Fix: `ALTER TABLE "widgets" ADD PRIMARY KEY ("id")`,
+			}},
+		},
+		{
+			name:    "primary key mismatch",
+			columns: widgetColumns, pk: []string{"name"}, unique: []string{"name"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.PrimaryKeyMismatch, Table: "widgets", Expected: "id", Actual: "name",
+				Fix: "ALTER TABLE \"widgets\" DROP PRIMARY KEY;\nALTER TABLE \"widgets\" ADD PRIMARY KEY (\"id\")",
+			}},
+		},
+		{
+			name:    "missing unique index",
+			columns: widgetColumns, pk: []string{"id"},
+			want: []oraschema.Difference{{
+				Kind: oraschema.MissingUniqueIndex, Table: "widgets", Expected: "name",
+				Fix: `ALTER TABLE "widgets" ADD UNIQUE ("name")`,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := orafake.New()
+			t.Cleanup(rec.Close)
+			db := rec.DB()
+			t.Cleanup(func() { db.Close() })
+			live(rec, tt.columns, tt.pk, tt.unique...)
+
+			got, err := oraschema.Compare(context.Background(), db, (*Widget)(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDifferenceString(t *testing.T) {
+	tests := []struct {
+		d    oraschema.Difference
+		want string
+	}{
+		{oraschema.Difference{Kind: oraschema.MissingTable, Table: "widgets"}, "missing table: widgets"},
+		{oraschema.Difference{Kind: oraschema.TypeMismatch, Table: "widgets", Column: "price",
+			Expected: "NUMBER(10,2)", Actual: "NUMBER(12,2)"},
+			"type mismatch: widgets.price (model NUMBER(10,2), database NUMBER(12,2))"},
+		{oraschema.Difference{Kind: oraschema.MissingColumn, Table: "widgets", Column: "sold", Expected: "DATE"},
+			"missing column: widgets.sold (model DATE, database none)"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
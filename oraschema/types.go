@@ -0,0 +1,121 @@
+package oraschema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraddl"
+	"github.com/uptrace/bun/schema"
+)
+
+// ModelType returns the SQL type oraddl creates for field: the one bun
+// uses in CREATE TABLE, or for a Decimal, Time or Duration without a type in
+// its tag the one oraddl picks.
+func ModelType(d schema.Dialect, field *schema.Field) string {
+	return oraddl.SQLType(d, field)
+}
+
+var typeRE = regexp.MustCompile(`^([A-Z][A-Z0-9_ ]*?)\s*(?:\(\s*(\*|\d+)\s*(?:,\s*(-?\d+)\s*)?(?:BYTE|CHAR)?\s*\))?\s*(WITH (?:LOCAL )?TIME ZONE)?$`)
+
+// CanonicalType rewrites a SQL type the way Oracle reports it in the data
+// dictionary, so that types written differently but stored identically
+// compare equal: INT and NUMBER(*,0) both become INTEGER, DOUBLE PRECISION
+// becomes FLOAT(126), TIMESTAMP becomes TIMESTAMP(6), and so on.
+func CanonicalType(typ string) string {
+	typ = strings.Join(strings.Fields(strings.ToUpper(typ)), " ")
+	if m := intervalRE.FindStringSubmatch(typ); m != nil {
+		return intervalType(m[1], m[2], m[3], m[4])
+	}
+	m := typeRE.FindStringSubmatch(typ)
+	if m == nil {
+		return typ
+	}
+	name, p, s, tz := m[1], m[2], m[3], m[4]
+
+	switch name {
+	case "INT", "INTEGER", "SMALLINT", "BIGINT":
+		return "INTEGER"
+	case "DOUBLE PRECISION":
+		return "FLOAT(126)"
+	case "REAL":
+		return "FLOAT(63)"
+	case "FLOAT":
+		if p == "" {
+			p = "126"
+		}
+		return "FLOAT(" + p + ")"
+	case "NUMBER", "NUMERIC", "DECIMAL", "DEC":
+		return numberType(p, s)
+	case "VARCHAR":
+		name = "VARCHAR2"
+	case "TIMESTAMP":
+		if p == "" {
+			p = "6"
+		}
+		typ = "TIMESTAMP(" + p + ")"
+		if tz != "" {
+			typ += " " + tz
+		}
+		return typ
+	}
+	if p != "" {
+		return name + "(" + p + ")"
+	}
+	return name
+}
+
+var intervalRE = regexp.MustCompile(`^INTERVAL (DAY|YEAR)\s*(?:\(\s*(\d+)\s*\))? TO (SECOND|MONTH)\s*(?:\(\s*(\d+)\s*\))?$`)
+
+// intervalType spells out the default precisions of an interval type, 2
+// for days and years and 6 for seconds, as the dictionary does.
+func intervalType(lead, p, unit, s string) string {
+	if p == "" {
+		p = "2"
+	}
+	typ := "INTERVAL " + lead + "(" + p + ") TO " + unit
+	if unit == "SECOND" {
+		if s == "" {
+			s = "6"
+		}
+		typ += "(" + s + ")"
+	}
+	return typ
+}
+
+func numberType(p, s string) string {
+	switch {
+	case (p == "" || p == "*") && s == "":
+		return "NUMBER"
+	case (p == "" || p == "*") && s == "0":
+		return "INTEGER"
+	case p == "" || p == "*":
+		return "NUMBER(*," + s + ")"
+	case s == "" || s == "0":
+		return "NUMBER(" + p + ")"
+	}
+	return "NUMBER(" + p + "," + s + ")"
+}
+
+// DictionaryType builds the type of c as it would be written in DDL.
+func (c *Column) DictionaryType() string {
+	switch c.DataType {
+	case "NUMBER":
+		return numberType(optInt(c.Precision), optInt(c.Scale))
+	case "FLOAT":
+		return "FLOAT(" + optInt(c.Precision) + ")"
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+		return fmt.Sprintf("%s(%d)", c.DataType, c.CharLength)
+	case "RAW":
+		return fmt.Sprintf("RAW(%d)", c.DataLength)
+	}
+	return c.DataType
+}
+
+func optInt(n *int64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatInt(*n, 10)
+}
@@ -0,0 +1,49 @@
+package oratest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// Golden compares got with testdata/golden/<name>.sql of the calling
+// package, or with testdata/golden/<name> when name has an extension of its
+// own. With go test -update it writes got to the file instead, so that the
+// change shows up in review as a diff of the golden file.
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+
+	if filepath.Ext(name) == "" {
+		name += ".sql"
+	}
+	path := filepath.Join("testdata", "golden", name)
+	if !hasNewline(got) {
+		got += "\n"
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s (run go test -update to accept)\ngot:\n%s\nwant:\n%s",
+			name, path, got, want)
+	}
+}
+
+func hasNewline(s string) bool {
+	return len(s) > 0 && s[len(s)-1] == '\n'
+}
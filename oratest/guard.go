@@ -0,0 +1,199 @@
+package oratest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrDDL is returned instead of executing a DDL statement inside a
+// rollback-only transaction. Oracle commits implicitly before and after
+// DDL, which would make the work of the test permanent.
+var ErrDDL = errors.New("oratest: DDL is not allowed inside a rollback-only transaction")
+
+// guardConnector wraps the connections of a driver.Connector so that DDL is
+// rejected and RELEASE SAVEPOINT, which bun issues when a nested
+// transaction commits but Oracle does not support, is a no-op.
+type guardConnector struct {
+	driver.Connector
+}
+
+func (c guardConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &guardConn{Conn: conn}, nil
+}
+
+type guardConn struct {
+	driver.Conn
+}
+
+var (
+	_ driver.ExecerContext      = (*guardConn)(nil)
+	_ driver.QueryerContext     = (*guardConn)(nil)
+	_ driver.ConnPrepareContext = (*guardConn)(nil)
+	_ driver.ConnBeginTx        = (*guardConn)(nil)
+	_ driver.NamedValueChecker  = (*guardConn)(nil)
+	_ driver.SessionResetter    = (*guardConn)(nil)
+	_ driver.Validator          = (*guardConn)(nil)
+	_ driver.Pinger             = (*guardConn)(nil)
+)
+
+type noopResult struct{}
+
+func (noopResult) LastInsertId() (int64, error) { return 0, nil }
+func (noopResult) RowsAffected() (int64, error) { return 0, nil }
+
+func (c *guardConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch classify(query) {
+	case stmtDDL:
+		return nil, ErrDDL
+	case stmtReleaseSavepoint:
+		return noopResult{}, nil
+	}
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *guardConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if classify(query) == stmtDDL {
+		return nil, ErrDDL
+	}
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *guardConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *guardConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if classify(query) == stmtDDL {
+		return nil, ErrDDL
+	}
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *guardConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *guardConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *guardConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *guardConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *guardConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+type stmtKind int
+
+const (
+	stmtOther stmtKind = iota
+	stmtDDL
+	stmtReleaseSavepoint
+)
+
+var ddlKeywords = map[string]bool{
+	"ALTER":     true,
+	"ANALYZE":   true,
+	"AUDIT":     true,
+	"COMMENT":   true,
+	"CREATE":    true,
+	"DROP":      true,
+	"FLASHBACK": true,
+	"GRANT":     true,
+	"NOAUDIT":   true,
+	"PURGE":     true,
+	"RENAME":    true,
+	"REVOKE":    true,
+	"TRUNCATE":  true,
+}
+
+// classify looks at the leading keywords of query. DDL hidden in PL/SQL
+// behind EXECUTE IMMEDIATE is not detected.
+func classify(query string) stmtKind {
+	words := leadingWords(query, 2)
+	if len(words) == 0 {
+		return stmtOther
+	}
+	switch {
+	case words[0] == "RELEASE" && len(words) > 1 && words[1] == "SAVEPOINT":
+		return stmtReleaseSavepoint
+	case words[0] == "ALTER" && len(words) > 1 && words[1] == "SESSION":
+		return stmtOther
+	case ddlKeywords[words[0]]:
+		return stmtDDL
+	}
+	return stmtOther
+}
+
+// leadingWords returns up to n upper-cased words of query, skipping
+// comments and whitespace.
+func leadingWords(query string, n int) []string {
+	var words []string
+	s := query
+	for len(words) < n {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(s, "--"):
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				s = s[i+1:]
+				continue
+			}
+			return words
+		case strings.HasPrefix(s, "/*"):
+			if i := strings.Index(s, "*/"); i >= 0 {
+				s = s[i+2:]
+				continue
+			}
+			return words
+		}
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '_'
+		})
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			return words
+		}
+		words = append(words, strings.ToUpper(s[:end]))
+		s = s[end:]
+	}
+	return words
+}
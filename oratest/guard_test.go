@@ -0,0 +1,89 @@
+package oratest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		query string
+		want  stmtKind
+	}{
+		{`CREATE TABLE t (id INTEGER)`, stmtDDL},
+		{`create or replace view v as select 1 from dual`, stmtDDL},
+		{`  DROP TABLE t PURGE`, stmtDDL},
+		{`TRUNCATE TABLE t`, stmtDDL},
+		{`ALTER TABLE t ADD (c INTEGER)`, stmtDDL},
+		{`GRANT SELECT ON t TO u`, stmtDDL},
+		{`COMMENT ON TABLE t IS 'x'`, stmtDDL},
+		{"-- comment\nCREATE INDEX i ON t (c)", stmtDDL},
+		{"/* comment */ DROP INDEX i", stmtDDL},
+		{`ALTER SESSION SET TIME_ZONE = 'UTC'`, stmtOther},
+		{`SELECT 1 FROM dual`, stmtOther},
+		{`INSERT INTO t (id) VALUES (1)`, stmtOther},
+		{`UPDATE t SET c = 1`, stmtOther},
+		{`DELETE FROM t`, stmtOther},
+		{`MERGE INTO t USING dual ON (1 = 1) WHEN MATCHED THEN UPDATE SET c = 1`, stmtOther},
+		{`SAVEPOINT sp1`, stmtOther},
+		{`ROLLBACK TO SAVEPOINT sp1`, stmtOther},
+		{`BEGIN EXECUTE IMMEDIATE 'DROP TABLE t'; END;`, stmtOther}, // not detected, as documented
+		{`RELEASE SAVEPOINT sp1`, stmtReleaseSavepoint},
+		{`release  savepoint "SP1"`, stmtReleaseSavepoint},
+		{`RELEASE`, stmtOther},
+		{``, stmtOther},
+		{`-- only a comment`, stmtOther},
+		{`/* unterminated`, stmtOther},
+	}
+	for _, tt := range tests {
+		if got := classify(tt.query); got != tt.want {
+			t.Errorf("classify(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestGuard(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	rec.On(`^SELECT`).Return([]string{"N"}, []any{1})
+	rec.On(`^UPDATE`).Affect(2)
+	db := sql.OpenDB(guardConnector{Connector: rec})
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE t (id INTEGER)`); !errors.Is(err, ErrDDL) {
+		t.Errorf("CREATE: got %v, want ErrDDL", err)
+	}
+	if _, err := db.QueryContext(ctx, `DROP TABLE t`); !errors.Is(err, ErrDDL) {
+		t.Errorf("query DROP: got %v, want ErrDDL", err)
+	}
+	if _, err := db.PrepareContext(ctx, `TRUNCATE TABLE t`); !errors.Is(err, ErrDDL) {
+		t.Errorf("prepare TRUNCATE: got %v, want ErrDDL", err)
+	}
+
+	res, err := db.ExecContext(ctx, `UPDATE t SET c = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("UPDATE: got %d rows affected, want 2", n)
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT 1 FROM dual`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("SELECT: got %d, %v", n, err)
+	}
+	if _, err := db.ExecContext(ctx, `RELEASE SAVEPOINT sp1`); err != nil {
+		t.Errorf("RELEASE SAVEPOINT: %v", err)
+	}
+
+	// DDL and RELEASE SAVEPOINT never reach the driver.
+	want := []string{`UPDATE t SET c = 1`, `SELECT 1 FROM dual`}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+package oratest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+// PDBTemplate clones a template pluggable database for tests that need
+// DBA-level changes (initialization parameters, tablespaces, users) which
+// schema isolation cannot contain.
+type PDBTemplate struct {
+	// Root is a connection to CDB$ROOT as a user with the CREATE PLUGGABLE
+	// DATABASE privilege, typically SYS AS SYSDBA.
+	Root *bun.DB
+	// Config is the connection config of Root. Clones reuse its address and
+	// options with the service name replaced.
+	Config oraconn.Config
+
+	// Name of the template PDB, e.g. "TEST_TEMPLATE".
+	Name string
+	// AdminUser and AdminPassword are the PDB admin account created in the
+	// template; clone configs connect as this user.
+	AdminUser     string
+	AdminPassword string
+	// FileDest is the directory data files are created in, passed as
+	// CREATE_FILE_DEST. It defaults to /opt/oracle/oradata, the data
+	// directory of the Oracle Database Free image.
+	FileDest string
+	// Snapshot requests SNAPSHOT COPY clones. It falls back to a full copy
+	// when the storage does not support snapshots.
+	Snapshot bool
+	// Setup runs once against a freshly created template, before it is made
+	// read-only, to install what every clone should start with.
+	Setup func(ctx context.Context, db *bun.DB) error
+
+	once    sync.Once
+	initErr error
+}
+
+// NewPDBTemplate connects to the CDB root with cfg.
+func NewPDBTemplate(cfg oraconn.Config, name, adminUser, adminPassword string) (*PDBTemplate, error) {
+	sqldb, err := cfg.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &PDBTemplate{
+		Root:          bun.NewDB(sqldb, oracledialect.New()),
+		Config:        cfg,
+		Name:          strings.ToUpper(name),
+		AdminUser:     strings.ToUpper(adminUser),
+		AdminPassword: adminPassword,
+	}, nil
+}
+
+// Close closes the root connection. The template itself is kept so later
+// test runs can reuse it; drop it with DROP PLUGGABLE DATABASE when done.
+func (p *PDBTemplate) Close() error {
+	return p.Root.Close()
+}
+
+// Clone creates a new PDB from the template for t, opens it, registers it
+// with the listener and returns a config targeting its service. The clone
+// is closed and dropped with its data files when the test finishes.
+func (p *PDBTemplate) Clone(t testing.TB) *oraconn.Config {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := p.init(ctx); err != nil {
+		t.Fatalf("oratest: preparing template %s: %v", p.Name, err)
+	}
+
+	name := "T" + randomHex(t, 12)
+	clone := fmt.Sprintf("CREATE PLUGGABLE DATABASE %s FROM %s CREATE_FILE_DEST = %s",
+		name, p.Name, quoteLiteral(p.fileDest()))
+	if p.Snapshot {
+		if err := p.exec(ctx, clone+" SNAPSHOT COPY"); err != nil {
+			t.Logf("oratest: snapshot copy of %s not available, using full copy: %v", p.Name, err)
+			p.dropPDB(ctx, name)
+		} else {
+			clone = ""
+		}
+	}
+	if clone != "" {
+		if err := p.exec(ctx, clone); err != nil {
+			t.Fatalf("oratest: cloning %s: %v", p.Name, err)
+		}
+	}
+	t.Cleanup(func() {
+		if err := p.dropPDB(context.Background(), name); err != nil {
+			t.Errorf("oratest: dropping %s: %v", name, err)
+		}
+	})
+
+	if err := p.execAll(ctx,
+		fmt.Sprintf("ALTER PLUGGABLE DATABASE %s OPEN", name),
+		"ALTER SYSTEM REGISTER",
+	); err != nil {
+		t.Fatalf("oratest: opening %s: %v", name, err)
+	}
+
+	return p.pdbConfig(name)
+}
+
+// init creates the template on first use unless it already exists, and
+// opens it read-only so it can be cloned.
+func (p *PDBTemplate) init(ctx context.Context) error {
+	p.once.Do(func() {
+		p.initErr = p.createTemplate(ctx)
+	})
+	return p.initErr
+}
+
+func (p *PDBTemplate) createTemplate(ctx context.Context) error {
+	var openMode string
+	err := p.Root.NewRaw("SELECT open_mode FROM v$pdbs WHERE name = ?", p.Name).Scan(ctx, &openMode)
+	switch {
+	case err == nil:
+		if openMode == "READ ONLY" {
+			return nil
+		}
+		return p.execAll(ctx,
+			fmt.Sprintf("ALTER PLUGGABLE DATABASE %s CLOSE IMMEDIATE", p.Name),
+			fmt.Sprintf("ALTER PLUGGABLE DATABASE %s OPEN READ ONLY", p.Name),
+		)
+	case !oraerr.IsNotFound(err):
+		return err
+	}
+
+	if err := p.execAll(ctx,
+		fmt.Sprintf(`CREATE PLUGGABLE DATABASE %s ADMIN USER %s IDENTIFIED BY "%s" ROLES = (DBA) CREATE_FILE_DEST = %s`,
+			p.Name, p.AdminUser, p.AdminPassword, quoteLiteral(p.fileDest())),
+		fmt.Sprintf("ALTER PLUGGABLE DATABASE %s OPEN", p.Name),
+		"ALTER SYSTEM REGISTER",
+	); err != nil {
+		return err
+	}
+
+	if p.Setup != nil {
+		sqldb, err := p.pdbConfig(p.Name).Open()
+		if err != nil {
+			return err
+		}
+		db := bun.NewDB(sqldb, oracledialect.New())
+		err = p.Setup(ctx, db)
+		db.Close()
+		if err != nil {
+			return fmt.Errorf("setup: %w", err)
+		}
+	}
+
+	return p.execAll(ctx,
+		fmt.Sprintf("ALTER PLUGGABLE DATABASE %s CLOSE IMMEDIATE", p.Name),
+		fmt.Sprintf("ALTER PLUGGABLE DATABASE %s OPEN READ ONLY", p.Name),
+	)
+}
+
+func (p *PDBTemplate) dropPDB(ctx context.Context, name string) error {
+	var n int
+	if err := p.Root.NewRaw("SELECT COUNT(*) FROM v$pdbs WHERE name = ?", name).Scan(ctx, &n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	return p.execAll(ctx,
+		fmt.Sprintf("ALTER PLUGGABLE DATABASE %s CLOSE IMMEDIATE", name),
+		fmt.Sprintf("DROP PLUGGABLE DATABASE %s INCLUDING DATAFILES", name),
+	)
+}
+
+// pdbConfig returns a config connecting to service as the PDB admin user.
+func (p *PDBTemplate) pdbConfig(service string) *oraconn.Config {
+	cfg := p.Config
+	cfg.Descriptor = ""
+	cfg.Service = service
+	cfg.User = p.AdminUser
+	cfg.Password = p.AdminPassword
+	cfg.Options = withoutDBAPrivilege(cfg.Options)
+	return &cfg
+}
+
+func (p *PDBTemplate) fileDest() string {
+	if p.FileDest != "" {
+		return p.FileDest
+	}
+	return "/opt/oracle/oradata"
+}
+
+func (p *PDBTemplate) exec(ctx context.Context, query string) error {
+	if _, err := p.Root.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%s: %w", redact(query), err)
+	}
+	return nil
+}
+
+func (p *PDBTemplate) execAll(ctx context.Context, queries ...string) error {
+	for _, query := range queries {
+		if err := p.exec(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withoutDBAPrivilege drops the SYSDBA option of the root connection, which
+// the PDB admin user does not have.
+func withoutDBAPrivilege(opts map[string]string) map[string]string {
+	out := make(map[string]string, len(opts))
+	for k, v := range opts {
+		if !strings.EqualFold(k, "DBA PRIVILEGE") {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func redact(query string) string {
+	if i := strings.Index(query, "IDENTIFIED BY"); i >= 0 {
+		if j := strings.Index(query[i:], " ROLES"); j >= 0 {
+			return query[:i] + `IDENTIFIED BY "***"` + query[i+j:]
+		}
+	}
+	return query
+}
@@ -0,0 +1,73 @@
+package oratest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+// RollbackDB hands out per-test transactions that are always rolled back,
+// for fast tests that do not need a schema of their own.
+type RollbackDB struct {
+	sqldb *sql.DB
+}
+
+// NewRollbackDB opens a pool for cfg whose connections reject DDL and
+// ignore RELEASE SAVEPOINT.
+func NewRollbackDB(cfg oraconn.Config) *RollbackDB {
+	sqldb := sql.OpenDB(guardConnector{Connector: cfg.Connector()})
+	cfg.ApplyPool(sqldb)
+	return &RollbackDB{sqldb: sqldb}
+}
+
+// Close closes the pool.
+func (r *RollbackDB) Close() error {
+	return r.sqldb.Close()
+}
+
+// Tx begins a transaction for t and returns it as a bun.IDB. The
+// transaction is rolled back when the test finishes.
+//
+// Code under test that calls BeginTx or RunInTx on the returned IDB gets a
+// savepoint instead of a new transaction, at any nesting depth: rolling it
+// back rolls back to the savepoint and committing it is a no-op, leaving
+// the changes to the outer rollback. Attempting DDL fails the test and the
+// statement returns ErrDDL without being executed.
+func (r *RollbackDB) Tx(t testing.TB) bun.IDB {
+	t.Helper()
+
+	db := bun.NewDB(r.sqldb, oracledialect.New())
+	db.AddQueryHook(ddlHook{t: t})
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("oratest: beginning transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			t.Errorf("oratest: rolling back: %v", err)
+		}
+	})
+	return tx
+}
+
+type ddlHook struct {
+	t testing.TB
+}
+
+var _ bun.QueryHook = ddlHook{}
+
+func (h ddlHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h ddlHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	if errors.Is(event.Err, ErrDDL) {
+		h.t.Errorf("oratest: DDL attempted in rollback-only transaction: %s", event.Query)
+	}
+}
@@ -0,0 +1,104 @@
+// Package oratest provides isolation helpers for integration tests that
+// share one Oracle database.
+package oratest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/lake-of-dreams/bundb-oracle/provision"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/oracledialect"
+)
+
+// Env is a shared database that hands out isolated schemas to tests.
+type Env struct {
+	// Admin is a connection privileged to create and drop users.
+	Admin *bun.DB
+	// Config is the admin connection config. Schema reuses its address,
+	// options and session settings for the per-test users.
+	Config oraconn.Config
+	// Tablespace holds the per-test schemas, "USERS" by default.
+	Tablespace string
+}
+
+// NewEnv opens an admin connection with cfg.
+func NewEnv(cfg oraconn.Config) (*Env, error) {
+	sqldb, err := cfg.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &Env{
+		Admin:      bun.NewDB(sqldb, oracledialect.New()),
+		Config:     cfg,
+		Tablespace: "USERS",
+	}, nil
+}
+
+// Close closes the admin connection.
+func (e *Env) Close() error {
+	return e.Admin.Close()
+}
+
+// Schema creates a uniquely named user for t and returns a bun.DB connected
+// as that user. The connection is closed and the user dropped CASCADE when
+// the test finishes, so tests calling Schema may run with t.Parallel.
+func (e *Env) Schema(t testing.TB) *bun.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	name := "T_" + randomHex(t, 10)
+	cfg, err := provision.CreateAppUser(ctx, e.Admin, e.Config, provision.AppUser{
+		Name:       name,
+		Password:   "P" + randomHex(t, 15),
+		Tablespace: e.Tablespace,
+	})
+	if err != nil {
+		dropUser(t, e.Admin, name)
+		t.Fatalf("oratest: creating schema for %s: %v", t.Name(), err)
+	}
+	cfg.Session.CurrentSchema = ""
+	cfg.Session.ClientIdentifier = clientIdentifier(t.Name())
+
+	sqldb, err := cfg.Open()
+	if err != nil {
+		dropUser(t, e.Admin, name)
+		t.Fatalf("oratest: connecting as %s: %v", name, err)
+	}
+	db := bun.NewDB(sqldb, oracledialect.New())
+
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("oratest: closing %s: %v", name, err)
+		}
+		dropUser(t, e.Admin, name)
+	})
+	return db
+}
+
+func dropUser(t testing.TB, admin *bun.DB, name string) {
+	if err := provision.DropUser(context.Background(), admin, name); err != nil {
+		t.Errorf("oratest: dropping %s: %v", name, err)
+	}
+}
+
+func randomHex(t testing.TB, n int) string {
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("oratest: %v", err)
+	}
+	return strings.ToUpper(hex.EncodeToString(b))[:n]
+}
+
+// clientIdentifier shows the test name in V$SESSION.CLIENT_IDENTIFIER,
+// which is limited to 64 bytes.
+func clientIdentifier(name string) string {
+	if len(name) > 64 {
+		name = name[len(name)-64:]
+	}
+	return name
+}
@@ -0,0 +1,243 @@
+// Package oratime maps Go times and durations to Oracle's datetime and
+// interval types without losing their time zone or precision.
+package oratime
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	go_ora "github.com/sijms/go-ora/v2"
+	"github.com/uptrace/bun/schema"
+)
+
+// Time is a time.Time that keeps its time zone through TIMESTAMP WITH TIME
+// ZONE columns. The zero value is NULL.
+//
+// bun writes a time.Time as a TIMESTAMP literal without a zone, which
+// Oracle reads in the session time zone. Time instead writes the instant
+// with its zone: a location loaded by region name, such as Europe/London,
+// as that region, which go-ora loads back on Scan, and any other location,
+// time.Local included, as its UTC offset at that instant. Times in the
+// repeated hour when clocks go back therefore keep their offset.
+//
+// The tag chooses the column type; without one oraddl creates TIMESTAMP
+// WITH TIME ZONE:
+//
+//	CreatedAt oratime.Time                                            // region or offset kept
+//	SeenAt    oratime.Time `bun:",type:timestamp with local time zone"` // instant kept, read in the session time zone
+//	Day       oratime.Time `bun:",type:date"`                           // wall clock kept, to the second
+//
+// Plain TIMESTAMP and DATE columns store the wall clock in the time's own
+// zone and drop the zone. See Precision for the fraction each type keeps.
+type Time struct {
+	time.Time
+}
+
+var (
+	_ sql.Scanner          = (*Time)(nil)
+	_ driver.Valuer        = Time{}
+	_ schema.QueryAppender = Time{}
+)
+
+// Scan implements sql.Scanner. NULL scans as the zero Time.
+func (t *Time) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*t = Time{}
+	case time.Time:
+		*t = Time{Time: src}
+	default:
+		return fmt.Errorf("oratime: cannot scan %T into a Time", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer. go-ora binds the result as a TIMESTAMP
+// WITH TIME ZONE.
+func (t Time) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return go_ora.TimeStampTZ(t.Time), nil
+}
+
+const timestampFormat = "2006-01-02 15:04:05.999999999"
+
+// AppendQuery writes t as a TIMESTAMP WITH TIME ZONE expression, e.g.
+//
+//	(FROM_TZ(TIMESTAMP '2026-10-25 00:30:00', 'UTC') AT TIME ZONE 'Europe/London')
+//	FROM_TZ(TIMESTAMP '2026-10-15 14:00:00', '+02:00')
+//
+// A region is written from the UTC instant, which is never ambiguous.
+func (t Time) AppendQuery(_ schema.Formatter, b []byte) ([]byte, error) {
+	if t.IsZero() {
+		return append(b, "NULL"...), nil
+	}
+	region := Region(t.Location())
+	if region == "" {
+		b = append(b, "FROM_TZ(TIMESTAMP '"...)
+		b = t.AppendFormat(b, timestampFormat)
+		b = append(b, "', '"...)
+		b = t.AppendFormat(b, "-07:00")
+		return append(b, "')"...), nil
+	}
+	if region == "UTC" {
+		b = append(b, "FROM_TZ(TIMESTAMP '"...)
+		b = t.UTC().AppendFormat(b, timestampFormat)
+		return append(b, "', 'UTC')"...), nil
+	}
+	b = append(b, "(FROM_TZ(TIMESTAMP '"...)
+	b = t.UTC().AppendFormat(b, timestampFormat)
+	b = append(b, "', 'UTC') AT TIME ZONE '"...)
+	b = append(b, region...)
+	return append(b, "')"...), nil
+}
+
+// Region returns the name Oracle knows loc by: UTC, or an IANA region name
+// such as America/New_York. It returns "" for time.Local and for fixed
+// zones, which only have an offset.
+func Region(loc *time.Location) string {
+	switch name := loc.String(); {
+	case loc == time.UTC || name == "UTC":
+		return "UTC"
+	case loc == time.Local:
+		return ""
+	case strings.Contains(name, "/") && !strings.ContainsAny(name, "'"):
+		return name
+	}
+	return ""
+}
+
+// Duration is a time.Duration stored as an INTERVAL DAY TO SECOND. Without
+// a type in its tag oraddl creates INTERVAL DAY(9) TO SECOND(6), which holds
+// any Duration to the microsecond; go-ora reads intervals to the microsecond
+// whatever the column's precision.
+type Duration struct {
+	time.Duration
+}
+
+var (
+	_ sql.Scanner          = (*Duration)(nil)
+	_ driver.Valuer        = Duration{}
+	_ schema.QueryAppender = Duration{}
+)
+
+// Scan implements sql.Scanner. NULL scans as 0. go-ora returns intervals
+// as strings such as "+01 02:03:04.500000".
+func (d *Duration) Scan(src any) error {
+	var err error
+	switch src := src.(type) {
+	case nil:
+		*d = Duration{}
+	case string:
+		d.Duration, err = parseInterval(src)
+	case []byte:
+		d.Duration, err = parseInterval(string(src))
+	default:
+		err = fmt.Errorf("oratime: cannot scan %T into a Duration", src)
+	}
+	return err
+}
+
+// Value implements driver.Valuer. The string converts to an interval
+// implicitly.
+func (d Duration) Value() (driver.Value, error) {
+	return string(d.appendInterval(nil)), nil
+}
+
+// AppendQuery writes d as an interval literal, e.g.
+// INTERVAL '+1 02:03:04.5' DAY(9) TO SECOND(9).
+func (d Duration) AppendQuery(_ schema.Formatter, b []byte) ([]byte, error) {
+	b = append(b, "INTERVAL '"...)
+	b = d.appendInterval(b)
+	return append(b, "' DAY(9) TO SECOND(9)"...), nil
+}
+
+// appendInterval appends d in Oracle's [+-]D HH:MI:SS.FF form.
+func (d Duration) appendInterval(b []byte) []byte {
+	v := d.Duration
+	if v < 0 {
+		b = append(b, '-')
+	} else {
+		b = append(b, '+')
+	}
+	// Split before negating, as the smallest Duration has no positive
+	// counterpart.
+	ns := v % time.Second
+	secs := int64(v / time.Second)
+	if v < 0 {
+		ns, secs = -ns, -secs
+	}
+	b = fmt.Appendf(b, "%d %02d:%02d:%02d", secs/86400, secs/3600%24, secs/60%60, secs%60)
+	if ns != 0 {
+		frac := strings.TrimRight(fmt.Sprintf("%09d", int64(ns)), "0")
+		b = append(b, '.')
+		b = append(b, frac...)
+	}
+	return b
+}
+
+var intervalRE = regexp.MustCompile(`^([+-]?)(\d+) (\d{1,2}):(\d{1,2}):(\d{1,2})(?:\.(\d{1,9}))?$`)
+
+func parseInterval(s string) (time.Duration, error) {
+	m := intervalRE.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("oratime: invalid interval %q", s)
+	}
+	var parts [4]int64
+	for i := range parts {
+		n, err := strconv.ParseInt(m[i+2], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("oratime: invalid interval %q", s)
+		}
+		parts[i] = n
+	}
+	d := time.Duration(parts[0])*24*time.Hour + time.Duration(parts[1])*time.Hour +
+		time.Duration(parts[2])*time.Minute + time.Duration(parts[3])*time.Second
+	if frac := m[6]; frac != "" {
+		ns, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		d += time.Duration(ns)
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+var precisionRE = regexp.MustCompile(`^(DATE|TIMESTAMP|INTERVAL DAY)(?:\s*\(\s*(\d)\s*\))?(?:\s+TO SECOND(?:\s*\(\s*(\d)\s*\))?)?(?:\s+WITH (?:LOCAL )?TIME ZONE)?$`)
+
+// Precision returns the smallest step a column of the Oracle type typ
+// stores: a second for DATE, 10^-p seconds for TIMESTAMP(p) and INTERVAL
+// DAY TO SECOND(p), where p defaults to 6. It returns 0 for other types.
+// Oracle rounds the values it stores to that step, so rounding a time with
+// time.Time.Round predicts what comes back.
+func Precision(typ string) time.Duration {
+	m := precisionRE.FindStringSubmatch(strings.Join(strings.Fields(strings.ToUpper(typ)), " "))
+	var p string
+	switch {
+	case m == nil:
+		return 0
+	case m[1] == "DATE" && m[2] == "" && m[3] == "":
+		return time.Second
+	case m[1] == "TIMESTAMP" && m[3] == "":
+		p = m[2]
+	case m[1] == "INTERVAL DAY" && strings.Contains(m[0], "TO SECOND"):
+		p = m[3]
+	default:
+		return 0
+	}
+	digits := 6
+	if p != "" {
+		digits, _ = strconv.Atoi(p)
+	}
+	step := time.Second
+	for range digits {
+		step /= 10
+	}
+	return step
+}
@@ -0,0 +1,250 @@
+package oratime_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oratime"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+type Event struct {
+	bun.BaseModel `bun:"table:events"`
+
+	ID   int64            `bun:",pk"`
+	At   oratime.Time     `bun:",nullzero"`
+	Seen oratime.Time     `bun:",nullzero,type:timestamp with local time zone"`
+	Day  oratime.Time     `bun:",nullzero,type:date"`
+	Took oratime.Duration `bun:",type:interval day(2) to second(6)"`
+}
+
+func load(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func appendTime(t *testing.T, tm time.Time) string {
+	t.Helper()
+	b, err := oratime.Time{Time: tm}.AppendQuery(schema.Formatter{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestAppendTime(t *testing.T) {
+	london := load(t, "Europe/London")
+	// Clocks in London go forward from 01:00 GMT to 02:00 BST on 29 March
+	// 2026 and back from 02:00 BST to 01:00 GMT on 25 October 2026.
+	beforeGap := time.Date(2026, 3, 29, 0, 59, 59, 0, london)
+	afterGap := beforeGap.Add(time.Second)
+	firstOneThirty := time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC).In(london)
+	secondOneThirty := firstOneThirty.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{"zero", time.Time{}, `NULL`},
+		{"utc", time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+			`FROM_TZ(TIMESTAMP '2026-10-15 12:00:00', 'UTC')`},
+		{"fixed zone", time.Date(2026, 10, 15, 14, 0, 0, 123456789, time.FixedZone("CEST", 2*3600)),
+			`FROM_TZ(TIMESTAMP '2026-10-15 14:00:00.123456789', '+02:00')`},
+		{"negative offset", time.Date(2026, 10, 15, 6, 30, 0, 0, time.FixedZone("", -(5*3600+30*60))),
+			`FROM_TZ(TIMESTAMP '2026-10-15 06:30:00', '-05:30')`},
+		{"before the gap", beforeGap,
+			`(FROM_TZ(TIMESTAMP '2026-03-29 00:59:59', 'UTC') AT TIME ZONE 'Europe/London')`},
+		{"after the gap", afterGap,
+			`(FROM_TZ(TIMESTAMP '2026-03-29 01:00:00', 'UTC') AT TIME ZONE 'Europe/London')`},
+		// 01:30 happens twice; the UTC instant tells them apart.
+		{"first 01:30", firstOneThirty,
+			`(FROM_TZ(TIMESTAMP '2026-10-25 00:30:00', 'UTC') AT TIME ZONE 'Europe/London')`},
+		{"second 01:30", secondOneThirty,
+			`(FROM_TZ(TIMESTAMP '2026-10-25 01:30:00', 'UTC') AT TIME ZONE 'Europe/London')`},
+		{"first 01:30 as an offset", firstOneThirty.In(time.FixedZone("BST", 3600)),
+			`FROM_TZ(TIMESTAMP '2026-10-25 01:30:00', '+01:00')`},
+		{"second 01:30 as an offset", secondOneThirty.In(time.FixedZone("GMT", 0)),
+			`FROM_TZ(TIMESTAMP '2026-10-25 01:30:00', '+00:00')`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendTime(t, tt.in); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		loc  *time.Location
+		want string
+	}{
+		{time.UTC, "UTC"},
+		{load(t, "UTC"), "UTC"},
+		{load(t, "America/New_York"), "America/New_York"},
+		{time.Local, ""},
+		{time.FixedZone("+02:00", 2*3600), ""},
+		{time.FixedZone("CET", 3600), ""},
+	}
+	for _, tt := range tests {
+		if got := oratime.Region(tt.loc); got != tt.want {
+			t.Errorf("Region(%s) = %q, want %q", tt.loc, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+
+	london := load(t, "Europe/London")
+	tokyo := load(t, "Asia/Tokyo")
+	at := time.Date(2026, 10, 25, 1, 30, 0, 0, time.UTC).In(london) // the second 01:30, GMT
+	in := &Event{
+		ID:   1,
+		At:   oratime.Time{Time: at},
+		Seen: oratime.Time{Time: at},
+		Day:  oratime.Time{Time: at.Add(1500 * time.Millisecond)},
+		Took: oratime.Duration{Duration: 36*time.Hour + 1500*time.Millisecond},
+	}
+	if _, err := db.NewInsert().Model(in).Exec(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	region := `(FROM_TZ(TIMESTAMP '2026-10-25 01:30:00', 'UTC') AT TIME ZONE 'Europe/London')`
+	want := []string{
+		`INSERT INTO "events" ("id", "at", "seen", "day", "took") VALUES (1, ` + region + `, ` + region + `, ` +
+			`(FROM_TZ(TIMESTAMP '2026-10-25 01:30:01.5', 'UTC') AT TIME ZONE 'Europe/London'), ` +
+			`INTERVAL '+1 12:00:01.5' DAY(9) TO SECOND(9))`,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// What go-ora returns for the row: TIMESTAMP WITH TIME ZONE in the
+	// stored region, WITH LOCAL TIME ZONE in the session time zone, here
+	// Tokyo's, DATE to the second without a zone, and the interval to the
+	// microsecond as a string.
+	day := at.Add(1500 * time.Millisecond).Round(oratime.Precision("date"))
+	rec.On(`^SELECT`).Return([]string{"id", "at", "seen", "day", "took"}, []any{
+		1, at, at.In(tokyo),
+		time.Date(day.Year(), day.Month(), day.Day(), day.Hour(), day.Minute(), day.Second(), 0, time.UTC),
+		"+01 12:00:01.500000",
+	})
+	out := new(Event)
+	if err := db.NewSelect().Model(out).WherePK().Scan(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !out.At.Equal(at) || out.At.Location().String() != "Europe/London" {
+		t.Errorf("At: got %v, want %v in Europe/London", out.At, at)
+	}
+	if _, offset := out.At.Zone(); offset != 0 {
+		t.Errorf("At: got offset %d, want GMT", offset)
+	}
+	// The session time zone changes the zone of a local time zone value,
+	// not its instant.
+	if !out.Seen.Equal(at) || out.Seen.Location() != tokyo {
+		t.Errorf("Seen: got %v, want %v in Asia/Tokyo", out.Seen, at)
+	}
+	// A DATE keeps the wall clock and rounds it to the second.
+	if got, want := out.Day.Format(time.DateTime), "2026-10-25 01:30:02"; got != want {
+		t.Errorf("Day: got %s, want %s", got, want)
+	}
+	if out.Took.Duration != in.Took.Duration {
+		t.Errorf("Took: got %v, want %v", out.Took, in.Took)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "+0 00:00:00"},
+		{time.Microsecond, "+0 00:00:00.000001"},
+		{36*time.Hour + 2*time.Minute + 3*time.Second + 500*time.Millisecond, "+1 12:02:03.5"},
+		{-90 * time.Minute, "-0 01:30:00"},
+		{-time.Nanosecond, "-0 00:00:00.000000001"},
+		{time.Duration(-1 << 63), "-106751 23:47:16.854775808"},
+	}
+	for _, tt := range tests {
+		v, err := oratime.Duration{Duration: tt.d}.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.d, v, tt.want)
+		}
+		var back oratime.Duration
+		if err := back.Scan(v); err != nil {
+			t.Errorf("Scan(%q): %v", v, err)
+		} else if back.Duration != tt.d {
+			t.Errorf("Scan(%q) = %v, want %v", v, back.Duration, tt.d)
+		}
+	}
+
+	// As go-ora formats intervals.
+	var d oratime.Duration
+	for s, want := range map[string]time.Duration{
+		"+00 00:00:00.000000": 0,
+		"-01 02:03:04.000005": -(26*time.Hour + 3*time.Minute + 4*time.Second + 5*time.Microsecond),
+		"+99 23:59:59.999999": 100*24*time.Hour - time.Microsecond,
+	} {
+		if err := d.Scan(s); err != nil {
+			t.Errorf("Scan(%q): %v", s, err)
+		} else if d.Duration != want {
+			t.Errorf("Scan(%q) = %v, want %v", s, d.Duration, want)
+		}
+	}
+	for _, s := range []string{"", "1", "+1 2:3", "+1 02:03:04.1234567890", "1 day"} {
+		if err := d.Scan(s); err == nil {
+			t.Errorf("Scan(%q): got no error", s)
+		}
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want time.Duration
+	}{
+		{"DATE", time.Second},
+		{"timestamp", time.Microsecond},
+		{"TIMESTAMP(0)", time.Second},
+		{"TIMESTAMP(3)", time.Millisecond},
+		{"TIMESTAMP(9) WITH TIME ZONE", time.Nanosecond},
+		{"timestamp with local time zone", time.Microsecond},
+		{"INTERVAL DAY TO SECOND", time.Microsecond},
+		{"interval day(9) to second(3)", time.Millisecond},
+		{"INTERVAL YEAR TO MONTH", 0},
+		{"VARCHAR2(20)", 0},
+	}
+	for _, tt := range tests {
+		if got := oratime.Precision(tt.typ); got != tt.want {
+			t.Errorf("Precision(%q) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+
+	// A TIMESTAMP(6) column rounds nanoseconds away, a DATE fractions of
+	// seconds.
+	at := time.Date(2026, 10, 15, 12, 0, 0, 999_999_500, time.UTC)
+	if got := at.Round(oratime.Precision("TIMESTAMP")); !got.Equal(time.Date(2026, 10, 15, 12, 0, 1, 0, time.UTC)) {
+		t.Errorf("TIMESTAMP rounds %v to %v", at, got)
+	}
+	if got := at.Round(oratime.Precision("DATE")); got.Second() != 1 {
+		t.Errorf("DATE rounds %v to %v", at, got)
+	}
+}
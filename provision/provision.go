@@ -0,0 +1,240 @@
+// Package provision creates least-privilege Oracle users for applications
+// built on bun, so that nothing has to run in the SYSTEM schema.
+package provision
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraconn"
+	"github.com/uptrace/bun"
+)
+
+// AppPrivileges are the system privileges granted to an application user:
+// enough for bun to create and use its tables, sequences, views and PL/SQL.
+var AppPrivileges = []string{
+	"CREATE SESSION",
+	"CREATE TABLE",
+	"CREATE SEQUENCE",
+	"CREATE VIEW",
+	"CREATE PROCEDURE",
+}
+
+var (
+	identRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]{0,127}$`)
+	sizeRE  = regexp.MustCompile(`^[0-9]+[KMGT]?$`)
+)
+
+// AppUser describes an application schema owner.
+type AppUser struct {
+	Name     string
+	Password string
+	// Tablespace defaults to Name. It is created when missing.
+	Tablespace string
+	// Datafile is the data file of a new tablespace. When empty the
+	// tablespace is created with Oracle Managed Files, which requires
+	// DB_CREATE_FILE_DEST to be set.
+	Datafile string
+	// DatafileSize is the initial size of the data file, "50M" by default.
+	DatafileSize string
+	// Quota on the tablespace, "UNLIMITED" by default.
+	Quota string
+}
+
+// ReadOnlyUser describes a user that may only query the tables of Owner.
+type ReadOnlyUser struct {
+	Name     string
+	Password string
+	Owner    string
+}
+
+// CreateAppUser creates u and its tablespace when missing and grants it
+// AppPrivileges. An existing user and its objects are left as they are.
+// It returns base with the credentials replaced by u's.
+func CreateAppUser(ctx context.Context, db bun.IDB, base oraconn.Config, u AppUser) (*oraconn.Config, error) {
+	name, err := ident(u.Name)
+	if err != nil {
+		return nil, err
+	}
+	tablespace := name
+	if u.Tablespace != "" {
+		if tablespace, err = ident(u.Tablespace); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPassword(u.Password); err != nil {
+		return nil, err
+	}
+	quota := u.Quota
+	if quota == "" {
+		quota = "UNLIMITED"
+	}
+	if quota, err = checkSize(quota, true); err != nil {
+		return nil, err
+	}
+	datafileSize := u.DatafileSize
+	if datafileSize == "" {
+		datafileSize = "50M"
+	}
+	if datafileSize, err = checkSize(datafileSize, false); err != nil {
+		return nil, err
+	}
+
+	if err := createTablespace(ctx, db, tablespace, u.Datafile, datafileSize); err != nil {
+		return nil, err
+	}
+
+	found, err := exists(ctx, db, "SELECT COUNT(*) FROM all_users WHERE username = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	var stmts []string
+	if !found {
+		stmts = append(stmts, fmt.Sprintf(
+			`CREATE USER %s IDENTIFIED BY "%s" DEFAULT TABLESPACE %s QUOTA %s ON %s`,
+			name, u.Password, tablespace, quota, tablespace))
+	}
+	stmts = append(stmts, fmt.Sprintf("GRANT %s TO %s", strings.Join(AppPrivileges, ", "), name))
+	if err := execAll(ctx, db, stmts); err != nil {
+		return nil, err
+	}
+
+	cfg := base
+	cfg.User = name
+	cfg.Password = u.Password
+	return &cfg, nil
+}
+
+// CreateReadOnlyUser creates u and grants it SELECT on every table and view
+// currently owned by u.Owner. The returned config is base with u's
+// credentials and CURRENT_SCHEMA set to the owner, so unqualified queries
+// resolve to the owner's objects.
+func CreateReadOnlyUser(ctx context.Context, db bun.IDB, base oraconn.Config, u ReadOnlyUser) (*oraconn.Config, error) {
+	name, err := ident(u.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPassword(u.Password); err != nil {
+		return nil, err
+	}
+
+	stmts := []string{
+		fmt.Sprintf(`CREATE USER %s IDENTIFIED BY "%s"`, name, u.Password),
+		fmt.Sprintf("GRANT CREATE SESSION TO %s", name),
+	}
+	if err := execAll(ctx, db, stmts); err != nil {
+		return nil, err
+	}
+	if err := GrantReadOnly(ctx, db, u.Owner, name); err != nil {
+		return nil, err
+	}
+
+	cfg := base
+	cfg.User = name
+	cfg.Password = u.Password
+	cfg.Session.CurrentSchema = strings.ToUpper(u.Owner)
+	return &cfg, nil
+}
+
+// GrantReadOnly grants reader SELECT on all tables and views of owner. Call
+// it again after the owner creates new objects.
+func GrantReadOnly(ctx context.Context, db bun.IDB, owner, reader string) error {
+	owner, err := ident(owner)
+	if err != nil {
+		return err
+	}
+	if reader, err = ident(reader); err != nil {
+		return err
+	}
+
+	var objects []string
+	if err := db.NewRaw(
+		"SELECT object_name FROM all_objects WHERE owner = ? AND object_type IN ('TABLE', 'VIEW') "+
+			"ORDER BY object_name", owner,
+	).Scan(ctx, &objects); err != nil {
+		return err
+	}
+
+	stmts := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		stmts = append(stmts, fmt.Sprintf(`GRANT SELECT ON %s."%s" TO %s`, owner, obj, reader))
+	}
+	return execAll(ctx, db, stmts)
+}
+
+// DropUser drops the user and all its objects if it exists.
+func DropUser(ctx context.Context, db bun.IDB, name string) error {
+	name, err := ident(name)
+	if err != nil {
+		return err
+	}
+	exists, err := exists(ctx, db, "SELECT COUNT(*) FROM all_users WHERE username = ?", name)
+	if err != nil || !exists {
+		return err
+	}
+	return execAll(ctx, db, []string{fmt.Sprintf("DROP USER %s CASCADE", name)})
+}
+
+func createTablespace(ctx context.Context, db bun.IDB, name, datafile, size string) error {
+	found, err := exists(ctx, db, "SELECT COUNT(*) FROM dba_tablespaces WHERE tablespace_name = ?", name)
+	if err != nil || found {
+		return err
+	}
+	file := ""
+	if datafile != "" {
+		file = " '" + strings.ReplaceAll(datafile, "'", "''") + "'"
+	}
+	return execAll(ctx, db, []string{fmt.Sprintf(
+		"CREATE TABLESPACE %s DATAFILE%s SIZE %s AUTOEXTEND ON NEXT 10M MAXSIZE UNLIMITED",
+		name, file, size)})
+}
+
+func exists(ctx context.Context, db bun.IDB, query string, args ...any) (bool, error) {
+	var n int
+	if err := db.NewRaw(query, args...).Scan(ctx, &n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func execAll(ctx context.Context, db bun.IDB, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("provision: %s: %w", redact(stmt), err)
+		}
+	}
+	return nil
+}
+
+// ident validates an unquoted Oracle identifier and returns it upper-cased,
+// the way Oracle stores it in the dictionary.
+func ident(s string) (string, error) {
+	if !identRE.MatchString(s) {
+		return "", fmt.Errorf("provision: invalid identifier %q", s)
+	}
+	return strings.ToUpper(s), nil
+}
+
+// checkSize validates a storage size such as "50M", or "UNLIMITED" when
+// unlimited is allowed, and returns it upper-cased.
+func checkSize(s string, unlimited bool) (string, error) {
+	if u := strings.ToUpper(s); sizeRE.MatchString(u) || unlimited && u == "UNLIMITED" {
+		return u, nil
+	}
+	return "", fmt.Errorf("provision: invalid size %q", s)
+}
+
+func checkPassword(s string) error {
+	if s == "" || strings.ContainsAny(s, "\"\x00") {
+		return fmt.Errorf("provision: password must be non-empty and must not contain '\"'")
+	}
+	return nil
+}
+
+var passwordRE = regexp.MustCompile(`IDENTIFIED BY "[^"]*"`)
+
+func redact(stmt string) string {
+	return passwordRE.ReplaceAllString(stmt, `IDENTIFIED BY "***"`)
+}
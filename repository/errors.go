@@ -0,0 +1,48 @@
+// Package repository wraps bun queries on the demo models behind
+// repositories with Oracle-aware pagination and typed errors. It is the
+// reference pattern for service code.
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound matches every NotFoundError.
+	ErrNotFound = errors.New("repository: not found")
+	// ErrConflict matches every ConflictError.
+	ErrConflict = errors.New("repository: conflict")
+)
+
+// NotFoundError reports that no row has the given key.
+type NotFoundError struct {
+	Entity string
+	Key    any
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("repository: %s %v not found", e.Entity, e.Key)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError reports that a write violated a unique constraint.
+type ConflictError struct {
+	Entity string
+	Err    error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("repository: %s conflicts with an existing row: %v", e.Entity, e.Err)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/oraddl"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// insertReturningID runs q with RETURNING field INTO an OUT bind that
+// receives the generated key. bun drops Returning from inserts on Oracle and
+// formats arguments into the query text, so the statement goes through the
+// database/sql handle under db, bypassing bun's query hooks.
+func insertReturningID(ctx context.Context, db bun.IDB, q *bun.InsertQuery, field *schema.Field, dest *int64) error {
+	conn, err := sqlConn(db)
+	if err != nil {
+		return err
+	}
+	// bun runs BeforeAppendModel hooks only for queries it executes.
+	if err := oraaudit.BeforeAppend(ctx, q); err != nil {
+		return err
+	}
+	query := q.String() + " RETURNING " + string(field.SQLName) + " INTO :1"
+	_, err = conn.ExecContext(ctx, query, sql.Out{Dest: dest})
+	return err
+}
+
+// sqlConn returns the database/sql handle of a bun.DB, bun.Conn or bun.Tx.
+func sqlConn(db bun.IDB) (bun.IConn, error) {
+	switch db := db.(type) {
+	case *bun.DB:
+		return db.DB, nil
+	case bun.Conn:
+		return db.Conn, nil
+	case *bun.Conn:
+		return db.Conn, nil
+	case bun.Tx:
+		return db.Tx, nil
+	case *bun.Tx:
+		return db.Tx, nil
+	}
+	return nil, fmt.Errorf("repository: unsupported bun.IDB %T", db)
+}
+
+// identitySequence returns the sequence that feeds the key column field, or
+// "" when the column takes no explicit values: it is GENERATED ALWAYS, or not
+// generated at all. A sequence or identity mode in the oraddl tag of field
+// (see oraddl.KeyGeneration) answers without asking the database, which is
+// otherwise asked for the sequence Oracle created for the identity column.
+func identitySequence(ctx context.Context, db bun.IDB, table *schema.Table, field *schema.Field) (string, error) {
+	k, err := oraddl.KeyGenerationOf(table, field)
+	switch {
+	case err != nil:
+		return "", err
+	case k.Sequence.Name != "":
+		return k.Sequence.Name, nil
+	case !k.AcceptsValues():
+		return "", nil
+	}
+
+	var name, generation string
+	err = db.NewRaw(
+		"SELECT sequence_name, generation_type FROM user_tab_identity_columns "+
+			"WHERE table_name = ? AND column_name = ?",
+		table.Name, field.Name,
+	).Scan(ctx, &name, &generation)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", nil
+	case err != nil:
+		return "", err
+	case generation == "ALWAYS":
+		return "", nil
+	}
+	return name, nil
+}
+
+// nextValues reserves n values of the sequence seq with one query.
+func nextValues(ctx context.Context, db bun.IDB, seq string, n int) ([]int64, error) {
+	var ids []int64
+	err := db.NewRaw("SELECT ?.NEXTVAL FROM dual CONNECT BY LEVEL <= ?", bun.Ident(seq), n).Scan(ctx, &ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != n {
+		return nil, fmt.Errorf("repository: got %d values of %s, want %d", len(ids), seq, n)
+	}
+	return ids, nil
+}
+
+// columnNames returns the column names of fields.
+func columnNames(fields []*schema.Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/lake-of-dreams/bundb-oracle/internal/oratx"
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/oraerr"
+	"github.com/lake-of-dreams/bundb-oracle/oralock"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/orapage"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+const productEntity = "product"
+
+// Sort orders a list by a column of the model.
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// ProductFilter restricts the products a query sees. Zero fields do not
+// filter.
+type ProductFilter struct {
+	// NamePattern is a LIKE pattern, e.g. "app%".
+	NamePattern string
+	MinPrice    *oranum.Decimal
+	MaxPrice    *oranum.Decimal
+	// WithDeleted includes products removed by Delete.
+	WithDeleted bool
+}
+
+type ProductListOptions struct {
+	ProductFilter
+	// Sort defaults to the primary key. The primary key is appended when
+	// missing, so that pages are stable.
+	Sort []Sort
+	// Limit is the page size; 0 means no limit.
+	Limit  int
+	Offset int
+}
+
+// ProductRepository reads and writes models.Product. It works on a bun.DB,
+// bun.Conn or bun.Tx alike.
+type ProductRepository struct {
+	db    bun.IDB
+	table *schema.Table
+}
+
+func NewProductRepository(db bun.IDB) *ProductRepository {
+	return &ProductRepository{
+		db:    db,
+		table: db.Dialect().Tables().Get(reflect.TypeOf((*models.Product)(nil))),
+	}
+}
+
+// Create inserts p and sets its ID.
+func (r *ProductRepository) Create(ctx context.Context, p *models.Product) error {
+	return r.wrap(insertReturningID(ctx, r.db, r.db.NewInsert().Model(p), r.table.PKs[0], &p.ID))
+}
+
+// BulkCreate inserts products and sets their IDs. Oracle cannot return keys
+// from a multi-row insert, so the IDs are first reserved from the sequence
+// behind the identity column, then inserted explicitly with one statement:
+// three round trips whatever the number of products. An identity column
+// GENERATED ALWAYS rejects explicit values; products are then inserted one
+// by one with RETURNING INTO, in a transaction. On error no ID is set.
+func (r *ProductRepository) BulkCreate(ctx context.Context, products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	pk := r.table.PKs[0]
+	seq, err := identitySequence(ctx, r.db, r.table, pk)
+	if err != nil {
+		return err
+	}
+
+	if seq == "" {
+		err = oratx.Run(ctx, r.db, func(ctx context.Context, db bun.IDB) error {
+			for i := range products {
+				p := &products[i]
+				if err := insertReturningID(ctx, db, db.NewInsert().Model(p), pk, &p.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	} else {
+		var ids []int64
+		if ids, err = nextValues(ctx, r.db, seq, len(products)); err != nil {
+			return err
+		}
+		for i := range products {
+			products[i].ID = ids[i]
+		}
+		// bun leaves identity columns out of inserts unless named.
+		_, err = r.db.NewInsert().Model(&products).Column(columnNames(r.table.Fields)...).Exec(ctx)
+	}
+	if err != nil {
+		for i := range products {
+			products[i].ID = 0
+		}
+	}
+	return r.wrap(err)
+}
+
+// Get returns the product with the given ID.
+func (r *ProductRepository) Get(ctx context.Context, id int64) (*models.Product, error) {
+	p := &models.Product{ID: id}
+	if err := r.db.NewSelect().Model(p).WherePK().Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &NotFoundError{Entity: productEntity, Key: id}
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// List returns a page of the products matching opts.
+func (r *ProductRepository) List(ctx context.Context, opts ProductListOptions) ([]models.Product, error) {
+	var products []models.Product
+	q := r.db.NewSelect().Model(&products).Apply(opts.ProductFilter.apply)
+
+	sorts := opts.Sort
+	if !hasColumn(sorts, "id") {
+		sorts = append(sorts[:len(sorts):len(sorts)], Sort{Column: "id"})
+	}
+	for _, s := range sorts {
+		field, ok := r.table.FieldMap[s.Column]
+		if !ok {
+			return nil, fmt.Errorf("repository: cannot sort products by %q", s.Column)
+		}
+		if s.Desc {
+			q = q.OrderExpr("? DESC", field.SQLName)
+		} else {
+			q = q.OrderExpr("? ASC", field.SQLName)
+		}
+	}
+
+	if err := orapage.OffsetFetch(r.db, q, opts.Offset, opts.Limit).Scan(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Count returns the number of products matching filter.
+func (r *ProductRepository) Count(ctx context.Context, filter ProductFilter) (int, error) {
+	return r.db.NewSelect().Model((*models.Product)(nil)).Apply(filter.apply).Count(ctx)
+}
+
+// Update writes the given columns of p, or all of them when none are given,
+// stamps it with oraaudit and increments its version. It returns an
+// *oralock.StaleObjectError when the product was changed since p was read,
+// and a *NotFoundError when it was deleted.
+func (r *ProductRepository) Update(ctx context.Context, p *models.Product, columns ...string) error {
+	stamped := oraaudit.UpdateColumns(r.table)
+	for _, col := range columns {
+		field, ok := r.table.FieldMap[col]
+		if !ok || field.IsPK || field.SkipUpdate() || field == oralock.VersionField(r.table) ||
+			slices.Contains(stamped, col) {
+			return fmt.Errorf("repository: cannot update product column %q", col)
+		}
+	}
+	if len(columns) > 0 {
+		columns = append(columns[:len(columns):len(columns)], stamped...)
+	}
+
+	err := oralock.Update(ctx, r.db, p, columns...)
+	if errors.Is(err, oralock.ErrStaleObject) {
+		if _, getErr := r.Get(ctx, p.ID); errors.Is(getErr, ErrNotFound) {
+			return getErr
+		}
+	}
+	return r.wrap(err)
+}
+
+// Delete soft-deletes the product with the given ID: the row stays, with
+// its deletion time set, but Get, List and Count no longer see it unless
+// asked to with WithDeleted. Deleting a deleted product returns a
+// *NotFoundError.
+func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.NewDelete().Model(&models.Product{ID: id}).WherePK().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(res, id)
+}
+
+// ForceDelete removes the row of the product with the given ID, whether or
+// not it was soft-deleted.
+func (r *ProductRepository) ForceDelete(ctx context.Context, id int64) error {
+	res, err := r.db.NewDelete().Model(&models.Product{ID: id}).WherePK().ForceDelete().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(res, id)
+}
+
+func (f ProductFilter) apply(q *bun.SelectQuery) *bun.SelectQuery {
+	if f.WithDeleted {
+		q = q.WhereAllWithDeleted()
+	}
+	if f.NamePattern != "" {
+		q = q.Where("? LIKE ?", bun.Ident("name"), f.NamePattern)
+	}
+	if f.MinPrice != nil {
+		q = q.Where("? >= ?", bun.Ident("price"), *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		q = q.Where("? <= ?", bun.Ident("price"), *f.MaxPrice)
+	}
+	return q
+}
+
+func (r *ProductRepository) checkAffected(res sql.Result, id int64) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &NotFoundError{Entity: productEntity, Key: id}
+	}
+	return nil
+}
+
+func (r *ProductRepository) wrap(err error) error {
+	if oraerr.IsUniqueViolation(err) {
+		return &ConflictError{Entity: productEntity, Err: err}
+	}
+	return err
+}
+
+func hasColumn(sorts []Sort, column string) bool {
+	for _, s := range sorts {
+		if s.Column == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lake-of-dreams/bundb-oracle/models"
+	"github.com/lake-of-dreams/bundb-oracle/oraaudit"
+	"github.com/lake-of-dreams/bundb-oracle/orafake"
+	"github.com/lake-of-dreams/bundb-oracle/oralock"
+	"github.com/lake-of-dreams/bundb-oracle/oranum"
+	"github.com/lake-of-dreams/bundb-oracle/repository"
+)
+
+func newRepo(t *testing.T) (*repository.ProductRepository, *orafake.Recorder) {
+	rec := orafake.New()
+	t.Cleanup(rec.Close)
+	db := rec.DB()
+	t.Cleanup(func() { db.Close() })
+	return repository.NewProductRepository(db), rec
+}
+
+// auditCtx stamps inserts and updates with a fixed time and user.
+func auditCtx() context.Context {
+	ctx := oraaudit.WithUser(context.Background(), "tester")
+	return oraaudit.WithTime(ctx, func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) })
+}
+
+// stamp is the time of auditCtx as bun writes it.
+const stamp = `FROM_TZ(TIMESTAMP '2026-10-15 12:00:00', 'UTC')`
+
+func TestListSQL(t *testing.T) {
+	repo, rec := newRepo(t)
+	minPrice, maxPrice := oranum.New(1, 0), oranum.MustParse("10.50")
+
+	_, err := repo.List(context.Background(), repository.ProductListOptions{
+		ProductFilter: repository.ProductFilter{NamePattern: "a%", MinPrice: &minPrice, MaxPrice: &maxPrice},
+		Sort:          []repository.Sort{{Column: "price", Desc: true}},
+		Limit:         10,
+		Offset:        20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `SELECT "u"."id", "u"."name", "u"."price", "u"."version", "u"."deleted_at", ` +
+		`"u"."created_at", "u"."updated_at", "u"."created_by", "u"."updated_by" FROM "products" "u" ` +
+		`WHERE ("name" LIKE 'a%') AND ("price" >= 1) AND ("price" <= 10.50) AND "u"."deleted_at" IS NULL ` +
+		`ORDER BY "price" DESC, "id" ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`
+	if got := rec.Queries(); len(got) != 1 || got[0] != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestListRejectsUnknownSort(t *testing.T) {
+	repo, rec := newRepo(t)
+
+	_, err := repo.List(context.Background(), repository.ProductListOptions{
+		Sort: []repository.Sort{{Column: "price; DROP TABLE products"}},
+	})
+	if err == nil {
+		t.Fatal("got no error for an unknown sort column")
+	}
+	if n := len(rec.Calls()); n != 0 {
+		t.Errorf("got %d statements, want none", n)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	repo, _ := newRepo(t)
+	ctx := context.Background()
+
+	if _, err := repo.Get(ctx, 42); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get: got %v, want ErrNotFound", err)
+	}
+	if err := repo.Update(ctx, &models.Product{ID: 42, Name: "x"}, "name"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Update: got %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, 42); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Delete: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	repo, rec := newRepo(t)
+	rec.On(`^(UPDATE|DELETE)`).Affect(1)
+	rec.On(`count`).Return([]string{"COUNT(*)"}, []any{3})
+	ctx := context.Background()
+
+	if err := repo.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ForceDelete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Count(ctx, repository.ProductFilter{WithDeleted: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := rec.Queries()
+	if len(got) != 3 {
+		t.Fatalf("got %q, want 3 statements", got)
+	}
+	if !strings.HasPrefix(got[0], `UPDATE "products" SET "deleted_at" = TO_TIMESTAMP(`) ||
+		!strings.HasSuffix(got[0], `WHERE "products"."deleted_at" IS NULL AND ("id" = 1)`) {
+		t.Errorf("Delete: got %q, want the deletion time set", got[0])
+	}
+	if want := `DELETE FROM "products" WHERE ("id" = 1)`; got[1] != want {
+		t.Errorf("ForceDelete: got %q, want %q", got[1], want)
+	}
+	if want := `SELECT count(*) FROM "products" "u"`; got[2] != want {
+		t.Errorf("Count: got %q, want %q", got[2], want)
+	}
+}
+
+func TestUpdateColumnMask(t *testing.T) {
+	repo, rec := newRepo(t)
+	rec.On(`^UPDATE`).Affect(1)
+	ctx := auditCtx()
+
+	if err := repo.Update(ctx, &models.Product{ID: 1, Name: "pear", Price: oranum.MustParse("2.5")}, "price"); err != nil {
+		t.Fatal(err)
+	}
+	want := `UPDATE "products" SET "price" = 2.5, "updated_at" = ` + stamp + `, "updated_by" = 'tester', "version" = 1 WHERE ("version" = 0) AND "products"."deleted_at" IS NULL AND ("id" = 1)`
+	if got := rec.Queries(); len(got) != 1 || got[0] != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, col := range []string{"id", "created_at", "updated_by"} {
+		if err := repo.Update(ctx, &models.Product{ID: 1}, col); err == nil {
+			t.Errorf("got no error updating %s", col)
+		}
+	}
+}
+
+func TestCreateConflict(t *testing.T) {
+	repo, rec := newRepo(t)
+	rec.On(`^INSERT`).Fail(orafake.OraError(1, "unique constraint violated"))
+
+	err := repo.Create(auditCtx(), &models.Product{Name: "apple"})
+	var conflict *repository.ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("got %v, want a ConflictError", err)
+	}
+
+	want := `INSERT INTO "products" ("name", "price", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") ` +
+		`VALUES ('apple', 0, NULL, ` + stamp + `, ` + stamp + `, 'tester', 'tester') RETURNING "id" INTO :1`
+	if got := rec.Queries(); len(got) != 1 || got[0] != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBulkCreateReservesIDs(t *testing.T) {
+	repo, rec := newRepo(t)
+	rec.On(`user_tab_identity_columns`).Return([]string{"SEQUENCE_NAME", "GENERATION_TYPE"},
+		[]any{"ISEQ$$_72000", "BY DEFAULT"},
+	)
+	rec.On(`NEXTVAL`).Return([]string{"NEXTVAL"}, []any{41}, []any{42})
+
+	products := []models.Product{{Name: "apple"}, {Name: "pear"}}
+	if err := repo.BulkCreate(auditCtx(), products); err != nil {
+		t.Fatal(err)
+	}
+	if products[0].ID != 41 || products[1].ID != 42 {
+		t.Errorf("got IDs %d and %d, want 41 and 42", products[0].ID, products[1].ID)
+	}
+	want := `INSERT INTO "products" ("id", "name", "price", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") ` +
+		`VALUES (41, 'apple', 0, 0, NULL, ` + stamp + `, ` + stamp + `, 'tester', 'tester'), ` +
+		`(42, 'pear', 0, 0, NULL, ` + stamp + `, ` + stamp + `, 'tester', 'tester')`
+	if got := rec.Queries(); len(got) != 3 || got[2] != want {
+		t.Errorf("got %q, want %q last", got, want)
+	}
+}
+
+func TestBulkCreateGeneratedAlways(t *testing.T) {
+	repo, rec := newRepo(t)
+	rec.On(`user_tab_identity_columns`).Return([]string{"SEQUENCE_NAME", "GENERATION_TYPE"},
+		[]any{"ISEQ$$_72000", "ALWAYS"},
+	)
+	rec.On(`^INSERT`).Times(1)
+	rec.On(`^INSERT`).Fail(orafake.OraError(1, "unique constraint violated"))
+
+	products := []models.Product{{Name: "apple"}, {Name: "apple"}}
+	err := repo.BulkCreate(auditCtx(), products)
+	if !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("got %v, want a conflict", err)
+	}
+	insert := `INSERT INTO "products" ("name", "price", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") ` +
+		`VALUES ('apple', 0, NULL, ` + stamp + `, ` + stamp + `, 'tester', 'tester') RETURNING "id" INTO :1`
+	want := []string{
+		`SELECT sequence_name, generation_type FROM user_tab_identity_columns WHERE table_name = 'products' AND column_name = 'id'`,
+		orafake.Begin,
+		insert,
+		insert,
+		orafake.Rollback,
+	}
+	if got := rec.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUpdateStale(t *testing.T) {
+	repo, rec := newRepo(t)
+	rec.On(`^SELECT`).Return([]string{"id", "name", "price", "version"}, []any{1, "pear", "2", 5})
+
+	err := repo.Update(context.Background(), &models.Product{ID: 1, Name: "apple", Version: 4})
+	if !errors.Is(err, oralock.ErrStaleObject) {
+		t.Fatalf("got %v, want ErrStaleObject", err)
+	}
+}